@@ -0,0 +1,159 @@
+package equifax
+
+import (
+	"context"
+	"sync"
+)
+
+// ApplicationState - известное клиенту состояние заявки в FPS.
+type ApplicationState struct {
+	ApplicationID string
+	Status        ApplicationStatus      // статус кредитной заявки
+	FraudStatus   ApplicationFraudStatus // фрод-статус кредитной заявки
+	DefaultStatus DefaultStatus          // дефолт-статус кредитной заявки
+}
+
+// ApplicationLifecycle ведет заявки через смену кредитного, фрод- и дефолт-статуса.
+//
+// Переходы, которые FPS заведомо отклонит, отклоняются до отправки запроса с тем же
+// StatusError, что вернул бы сервис: значение «Закрыт автоматически» (8) - со статусом 26-28,
+// повтор уже установленного значения - со статусом 23-25.
+type ApplicationLifecycle struct {
+	fraud EquifaxFraudContext
+
+	mu     sync.Mutex
+	states map[string]*ApplicationState
+}
+
+func NewApplicationLifecycle(fraud EquifaxFraudContext) *ApplicationLifecycle {
+	return &ApplicationLifecycle{
+		fraud:  fraud,
+		states: make(map[string]*ApplicationState),
+	}
+}
+
+// Track задает состояние заявки, переданной в FPS ранее, например до перезапуска приложения.
+func (l *ApplicationLifecycle) Track(state ApplicationState) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.states[state.ApplicationID] = &state
+}
+
+// State возвращает известное состояние заявки.
+func (l *ApplicationLifecycle) State(applicationID string) (ApplicationState, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state, ok := l.states[applicationID]
+	if !ok {
+		return ApplicationState{}, false
+	}
+	return *state, true
+}
+
+func (l *ApplicationLifecycle) NewApplication(ctx context.Context, req *NewApplication) (*NewApplicationResponse, error) {
+	resp, err := l.fraud.NewApplicationContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Track(ApplicationState{
+		ApplicationID: req.ApplicationID,
+		Status:        req.ApplicationStatus,
+		FraudStatus:   req.ApplicationFraudStatus,
+		DefaultStatus: req.DefaultStatus,
+	})
+	return resp, nil
+}
+
+func (l *ApplicationLifecycle) UpdateCreditStatus(ctx context.Context, req *UpdateCreditStatus) (*UpdateCreditStatusResponse, error) {
+	err := l.check(req.ApplicationID, req.ApplicationStatus == ApplicationStatusType8, StatusType26, func(s *ApplicationState) bool {
+		return s.Status == req.ApplicationStatus
+	}, StatusType23)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := l.fraud.UpdateCreditStatusContext(ctx, req)
+	l.update(req.ApplicationID, err, StatusType23, func(s *ApplicationState) {
+		s.Status = req.ApplicationStatus
+	})
+	return resp, err
+}
+
+func (l *ApplicationLifecycle) UpdateFraudStatus(ctx context.Context, req *UpdateFraudStatus) (*UpdateFraudStatusResponse, error) {
+	err := l.check(req.ApplicationID, req.ApplicationFraudStatus == ApplicationFraudStatusType8, StatusType27, func(s *ApplicationState) bool {
+		return s.FraudStatus == req.ApplicationFraudStatus
+	}, StatusType24)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := l.fraud.UpdateFraudStatusContext(ctx, req)
+	l.update(req.ApplicationID, err, StatusType24, func(s *ApplicationState) {
+		s.FraudStatus = req.ApplicationFraudStatus
+	})
+	return resp, err
+}
+
+func (l *ApplicationLifecycle) UpdateDefaultStatus(ctx context.Context, req *UpdateDefaultStatus) (*UpdateDefaultStatusResponse, error) {
+	err := l.check(req.ApplicationID, req.DefaultStatus == DefaultStatusType8, StatusType28, func(s *ApplicationState) bool {
+		return s.DefaultStatus == req.DefaultStatus
+	}, StatusType25)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := l.fraud.UpdateDefaultStatusContext(ctx, req)
+	l.update(req.ApplicationID, err, StatusType25, func(s *ApplicationState) {
+		s.DefaultStatus = req.DefaultStatus
+	})
+	return resp, err
+}
+
+func (l *ApplicationLifecycle) DeleteApplication(ctx context.Context, req *DeleteApplication) (*DeleteApplicationResponse, error) {
+	resp, err := l.fraud.DeleteApplicationContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	delete(l.states, req.ApplicationID)
+	l.mu.Unlock()
+	return resp, nil
+}
+
+// check отклоняет установку внутреннего значения 8 и повтор уже установленного значения.
+func (l *ApplicationLifecycle) check(applicationID string, internal bool, internalStatus Status, repeated func(*ApplicationState) bool, repeatedStatus Status) error {
+	if internal {
+		return &StatusError{ApplicationID: applicationID, Status: internalStatus}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if state, ok := l.states[applicationID]; ok && repeated(state) {
+		return &StatusError{ApplicationID: applicationID, Status: repeatedStatus}
+	}
+	return nil
+}
+
+// update запоминает новое значение, если сервис его принял или сообщил, что оно уже установлено.
+func (l *ApplicationLifecycle) update(applicationID string, err error, repeatedStatus Status, set func(*ApplicationState)) {
+	if err != nil {
+		statusErr, ok := asStatusError(err)
+		if !ok || statusErr.Status != repeatedStatus {
+			return
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state, ok := l.states[applicationID]
+	if !ok {
+		state = &ApplicationState{ApplicationID: applicationID}
+		l.states[applicationID] = state
+	}
+	set(state)
+}
@@ -1,10 +1,11 @@
 package equifax
 
 import (
-	"encoding/csv"
-	"io"
+	"bytes"
+	"strings"
 
-	"github.com/gocarina/gocsv"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/transform"
 )
 
 type batchFraud struct {
@@ -14,18 +15,33 @@ func NewBatchFraud() *batchFraud {
 	return &batchFraud{}
 }
 
-func csvWriter(out io.Writer) *csv.Writer {
-	writer := csv.NewWriter(out)
-	writer.Comma = '\t'
-	return writer
-}
-
 func (*batchFraud) ToCSV(req []*NewApplication) (string, error) {
-	gocsv.SetCSVWriter(csvWriter)
-
-	res, err := gocsv.MarshalString(&req)
-	if err != nil {
+	buffer := new(bytes.Buffer)
+	if err := NewBatchWriter(buffer).WriteAll(req); err != nil {
 		return "", err
 	}
-	return res, nil
+	return buffer.String(), nil
+}
+
+// Encode возвращает файл заявок в кодировке windows-1251.
+func (b *batchFraud) Encode(req []*NewApplication) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	enc := transform.NewWriter(buffer, charmap.Windows1251.NewEncoder())
+	if err := NewBatchWriter(enc).WriteAll(req); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// FromCSV разбирает файл заявок, полученный ToCSV.
+func (*batchFraud) FromCSV(data string) ([]*NewApplication, error) {
+	return NewBatchReader(strings.NewReader(data)).ReadAll()
+}
+
+// Decode разбирает файл заявок в кодировке windows-1251, полученный Encode.
+func (*batchFraud) Decode(data []byte) ([]*NewApplication, error) {
+	return NewBatchReader(charmap.Windows1251.NewDecoder().Reader(bytes.NewReader(data))).ReadAll()
 }
@@ -0,0 +1,196 @@
+package equifax
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// csvUnmarshaler реализуют типы полей со своим представлением в файле заявок (EmptyString, Time, Date).
+type csvUnmarshaler interface {
+	UnmarshalCSV(value string) error
+}
+
+var csvUnmarshalerType = reflect.TypeOf((*csvUnmarshaler)(nil)).Elem()
+
+// BatchRowError - ошибка разбора строки файла заявок.
+type BatchRowError struct {
+	Line   int    // номер строки файла, начиная с 1
+	Column string // колонка с ошибкой; пустая, если строка не разобрана целиком
+	Err    error
+}
+
+func (e *BatchRowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("equifax: batch line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("equifax: batch line %d: %s: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *BatchRowError) Unwrap() error {
+	return e.Err
+}
+
+// BatchErrors - ошибки всех строк, которые не удалось разобрать.
+type BatchErrors []*BatchRowError
+
+func (e BatchErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// BatchReader построчно читает заявки из файла пакетной загрузки в формате BatchWriter.
+// Колонки сопоставляются по заголовку, поэтому их порядок и состав могут отличаться.
+type BatchReader struct {
+	Comma rune // разделитель полей, по умолчанию '\t'
+
+	in      io.Reader
+	r       *csv.Reader
+	columns []*batchColumn
+	err     error // ошибка чтения заголовка
+}
+
+func NewBatchReader(in io.Reader) *BatchReader {
+	return &BatchReader{Comma: '\t', in: in}
+}
+
+func (r *BatchReader) reader() (*csv.Reader, error) {
+	if r.r == nil && r.err == nil {
+		r.r = csv.NewReader(r.in)
+		r.r.Comma = r.Comma
+		r.columns, r.err = readBatchHeader(r.r)
+	}
+	return r.r, r.err
+}
+
+func readBatchHeader(cr *csv.Reader) ([]*batchColumn, error) {
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*batchColumn, len(batchColumns))
+	for i := range batchColumns {
+		byName[batchColumns[i].name] = &batchColumns[i]
+	}
+
+	columns := make([]*batchColumn, len(header))
+	for i, name := range header {
+		c, ok := byName[strings.TrimSpace(name)]
+		if !ok {
+			return nil, errors.Errorf("equifax: batch line 1: unknown column %q", name)
+		}
+		columns[i] = c
+	}
+	cr.FieldsPerRecord = len(header)
+	return columns, nil
+}
+
+// Read возвращает следующую заявку; io.EOF - в конце файла. Ошибка разбора строки
+// возвращается как *BatchRowError, после нее чтение можно продолжить.
+func (r *BatchReader) Read() (*NewApplication, error) {
+	cr, err := r.reader()
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := cr.Read()
+	if err != nil {
+		if parseErr, ok := err.(*csv.ParseError); ok {
+			return nil, &BatchRowError{Line: parseErr.StartLine, Err: parseErr.Err}
+		}
+		return nil, err
+	}
+	line, _ := cr.FieldPos(0)
+
+	app := new(NewApplication)
+	v := reflect.ValueOf(app).Elem()
+	for i, value := range record {
+		if err := setCSVValue(v.FieldByIndex(r.columns[i].index), value); err != nil {
+			return nil, &BatchRowError{Line: line, Column: r.columns[i].name, Err: err}
+		}
+	}
+	return app, nil
+}
+
+// ReadAll читает заявки до конца файла. Строки с ошибками пропускаются, ошибки
+// возвращаются вместе с разобранными заявками как BatchErrors.
+func (r *BatchReader) ReadAll() ([]*NewApplication, error) {
+	var (
+		apps []*NewApplication
+		errs BatchErrors
+	)
+
+	for {
+		app, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if rowErr, ok := err.(*BatchRowError); ok {
+			errs = append(errs, rowErr)
+			continue
+		}
+		if err != nil {
+			return apps, err
+		}
+		apps = append(apps, app)
+	}
+
+	if len(errs) > 0 {
+		return apps, errs
+	}
+	return apps, nil
+}
+
+func setCSVValue(v reflect.Value, value string) error {
+	if v.Addr().Type().Implements(csvUnmarshalerType) {
+		return v.Addr().Interface().(csvUnmarshaler).UnmarshalCSV(value)
+	}
+
+	if v.Kind() == reflect.String {
+		v.SetString(value)
+		return nil
+	}
+	if strings.TrimSpace(value) == "" {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(strings.TrimSpace(value), 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(strings.TrimSpace(value), v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported csv type %s", v.Type())
+	}
+	return nil
+}
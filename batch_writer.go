@@ -0,0 +1,153 @@
+package equifax
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// csvMarshaler реализуют типы полей со своим представлением в файле заявок (EmptyString, Time, Date).
+type csvMarshaler interface {
+	MarshalCSV() (string, error)
+}
+
+var csvMarshalerType = reflect.TypeOf((*csvMarshaler)(nil)).Elem()
+
+type batchColumn struct {
+	name  string
+	index []int
+}
+
+// batchColumns - колонки файла заявок по тегам csv полей NewApplication; вычисляются один раз.
+var batchColumns = csvColumns(reflect.TypeOf(NewApplication{}), nil)
+
+func csvColumns(t reflect.Type, index []int) []batchColumn {
+	var columns []batchColumn
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fieldIndex := append(append([]int(nil), index...), i)
+
+		tag := strings.Split(f.Tag.Get("csv"), ",")[0]
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			columns = append(columns, csvColumns(f.Type, fieldIndex)...)
+			continue
+		}
+		if tag == "-" || f.PkgPath != "" {
+			continue
+		}
+		if tag == "" {
+			tag = f.Name
+		}
+		columns = append(columns, batchColumn{name: tag, index: fieldIndex})
+	}
+	return columns
+}
+
+// BatchWriter построчно пишет заявки в файл пакетной загрузки. В памяти хранится только
+// текущая строка, поэтому объем выгрузки не ограничен. Заголовок пишется перед первой заявкой.
+//
+// Comma и UseCRLF задаются до первой записи и не влияют на другие CSV-writer процесса.
+type BatchWriter struct {
+	Comma   rune // разделитель полей, по умолчанию '\t'
+	UseCRLF bool
+
+	out    io.Writer
+	w      *csv.Writer
+	record []string
+}
+
+func NewBatchWriter(out io.Writer) *BatchWriter {
+	return &BatchWriter{Comma: '\t', out: out}
+}
+
+func (w *BatchWriter) writer() (*csv.Writer, error) {
+	if w.w != nil {
+		return w.w, nil
+	}
+
+	w.w = csv.NewWriter(w.out)
+	w.w.Comma = w.Comma
+	w.w.UseCRLF = w.UseCRLF
+
+	w.record = make([]string, len(batchColumns))
+	for i, c := range batchColumns {
+		w.record[i] = c.name
+	}
+	return w.w, w.w.Write(w.record)
+}
+
+// Write пишет заявку в файл.
+func (w *BatchWriter) Write(app *NewApplication) error {
+	cw, err := w.writer()
+	if err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(app).Elem()
+	for i, c := range batchColumns {
+		if w.record[i], err = csvValue(v.FieldByIndex(c.index)); err != nil {
+			return fmt.Errorf("application %s: %s: %v", app.ApplicationID, c.name, err)
+		}
+	}
+	return cw.Write(w.record)
+}
+
+// WriteAll пишет заявки и сбрасывает буфер.
+func (w *BatchWriter) WriteAll(apps []*NewApplication) error {
+	for _, app := range apps {
+		if err := w.Write(app); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// WriteChan пишет заявки из канала, пока он не будет закрыт, и сбрасывает буфер.
+// При ошибке оставшиеся заявки из канала не читаются.
+func (w *BatchWriter) WriteChan(apps <-chan *NewApplication) error {
+	for app := range apps {
+		if err := w.Write(app); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// Flush записывает буферизованные строки в io.Writer. Для пустой выгрузки пишется только заголовок.
+func (w *BatchWriter) Flush() error {
+	cw, err := w.writer()
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func csvValue(v reflect.Value) (string, error) {
+	if v.CanAddr() && v.Addr().Type().Implements(csvMarshalerType) {
+		return v.Addr().Interface().(csvMarshaler).MarshalCSV()
+	}
+	if v.Type().Implements(csvMarshalerType) {
+		return v.Interface().(csvMarshaler).MarshalCSV()
+	}
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	}
+	return "", fmt.Errorf("unsupported csv type %s", v.Type())
+}
@@ -0,0 +1,79 @@
+package equifax
+
+// LegalEntityRequest - запрос кредитного отчета по юр. лицу. Build собирает из него
+// CreditRequest с блоками commercial в титульной части и в заявлении.
+type LegalEntityRequest struct {
+	Num            int                    // ID запроса
+	Type           string                 // идентификатор отчета
+	DateOfReport   Date                   // дата, на которую запрашивается отчет
+	Reason         Reason                 // цель согласия
+	ReasonText     string                 // иная цель согласия
+	Company        LegalEntity            // юр. лицо
+	Application    ApplicationLegalEntity // сведения о юр. лице для заявления
+	Consent        Consent                // флаг согласия юр. лица на получение его кредитного отчета
+	AdmCodeInForm  AdmCodeInForm          // флаг информирования пользователя КИ об административной ответственности
+	ConsentDate    Date                   // дата выдачи согласия
+	ConsentEndDate Date                   // дата окончания действия согласия
+	ConsentOwner   string                 // пользователь КИ, получивший согласие
+	AddressReg     *AddressReg            // адрес регистрации (юридический адрес)
+	AddressFact    *AddressFact           // фактический адрес; если не задан, совпадает с адресом регистрации
+}
+
+// Build возвращает CreditRequest по юр. лицу и ошибку CreditValidationError, если запрос
+// не пройдет проверки бюро, в том числе если не заданы Consent или AdmCodeInForm
+// (ResponseCodeType30). У резидента без RegCountry государством регистрации считается Россия.
+func (r *LegalEntityRequest) Build() (*CreditRequest, error) {
+	company := r.Company
+	if company.Resident == ResidentType1 && company.RegCountry == "" {
+		company.RegCountry = CountryTypeRU
+	}
+	application := r.Application
+
+	req := &CreditRequest{
+		Num:          r.Num,
+		Type:         r.Type,
+		DateOfReport: r.DateOfReport,
+		Reason:       r.Reason,
+		ReasonText:   r.ReasonText,
+		LegalEntity:  &company,
+		Application: &Application{
+			Consent:        r.Consent,
+			AdmCodeInForm:  r.AdmCodeInForm,
+			ConsentDate:    r.ConsentDate,
+			ConsentEndDate: r.ConsentEndDate,
+			ConsentOwner:   r.ConsentOwner,
+			LegalEntity:    &application,
+		},
+		AddressReg:  r.AddressReg,
+		AddressFact: r.AddressFact,
+	}
+	if req.AddressFact == nil && r.AddressReg != nil {
+		a := r.AddressReg
+		req.AddressFact = &AddressFact{
+			Owner:     a.Owner,
+			Index:     a.Index,
+			AddrTotal: a.AddrTotal,
+			Country:   a.Country,
+			Region:    a.Region,
+			City:      a.City,
+			District:  a.District,
+			Street:    a.Street,
+			House:     a.House,
+			Flat:      a.Flat,
+		}
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Validate проверяет титульную часть юр. лица: для резидента ИНН из 10 цифр и ОГРН из 13
+// цифр с контрольными цифрами и государство регистрации RU, для нерезидента - государство
+// регистрации, отличное от RU.
+func (c *LegalEntity) Validate() error {
+	v := new(creditValidator)
+	c.validate(v)
+	return v.err()
+}
@@ -0,0 +1,229 @@
+package equifax
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/l-vitaly/acharset"
+	"github.com/pkg/errors"
+)
+
+// MaxPackageRecords - наибольшее количество запросов в пакете: reccount (typePackageRecCount)
+// содержит не более 4 цифр.
+const MaxPackageRecords = 9999
+
+var (
+	ErrPackageEmpty        = errors.New("package has no requests")
+	ErrPackageDuplicateNum = errors.New("request num is not unique in package")
+	ErrResponseMissing     = errors.New("package response has no response for request")
+)
+
+// CreditPackageResult - результат запроса из пакета.
+type CreditPackageResult struct {
+	Request   *CreditRequest
+	Response  *Response      // ответ бюро с тем же num; nil, если запрос не отправлен или ответа нет
+	Err       error          // ошибка запроса: проверки, отправки пакета или код ответа, как в Get
+	Package   string         // идентификатор пакета, в котором отправлен запрос
+	Signature *SignatureInfo // результат проверки подписи ответа на пакет
+}
+
+type creditPackageResponse struct {
+	XMLName   xml.Name    `xml:"bki_response"`
+	Version   string      `xml:"version,attr"`
+	PartnerID string      `xml:"partnerid,attr"`
+	PackageID string      `xml:"packageid,attr"`
+	Responses []*Response `xml:"response"`
+}
+
+var packageSeq uint32
+
+// newPackageID возвращает идентификатор пакета (typePackageID, до 15 цифр): время отправки
+// с точностью до минуты и номер пакета в процессе.
+func newPackageID() string {
+	seq := atomic.AddUint32(&packageSeq, 1) % 100000
+	return fmt.Sprintf("%s%05d", time.Now().In(BureauLocation).Format("0601021504"), seq)
+}
+
+func (e *equifaxCredit) GetPackage(r []*CreditRequest) ([]*CreditPackageResult, error) {
+	return e.GetPackageContext(context.Background(), r)
+}
+
+// GetPackageContext отправляет запросы одним bki_request на пакет из не более чем
+// MaxPackageRecords запросов; пакеты отправляются последовательно. Ответы сопоставляются
+// с запросами по num, поэтому num запросов должны быть различны.
+//
+// GetPackage и GetPackageContext не входят в EquifaxCreditContext и CreditClient: схема
+// bki_request допускает один request и не описывает packageid и reccount, поэтому конверт
+// пакета нужно подтвердить в бюро, прежде чем открывать эти вызовы.
+//
+// Ошибка возвращается, только если запросы не могут быть отправлены вовсе. Ошибки отдельных
+// запросов и пакетов (проверка, подпись, отправка, код ответа) возвращаются в
+// CreditPackageResult.Err; при отмене ctx неотправленные запросы получают ctx.Err().
+func (e *equifaxCredit) GetPackageContext(ctx context.Context, r []*CreditRequest) ([]*CreditPackageResult, error) {
+	if len(r) == 0 {
+		return nil, ErrPackageEmpty
+	}
+
+	nums := make(map[int]bool, len(r))
+	results := make([]*CreditPackageResult, len(r))
+	for i, req := range r {
+		if nums[req.Num] {
+			return nil, errors.Wrapf(ErrPackageDuplicateNum, "num %d", req.Num)
+		}
+		nums[req.Num] = true
+		results[i] = &CreditPackageResult{Request: req}
+	}
+
+	var pending []*CreditPackageResult
+	for _, res := range results {
+		if res.Err = e.checkRequest(res.Request); res.Err == nil {
+			pending = append(pending, res)
+		}
+	}
+
+	for len(pending) > 0 {
+		n := len(pending)
+		if n > MaxPackageRecords {
+			n = MaxPackageRecords
+		}
+		chunk := pending[:n]
+		pending = pending[n:]
+
+		if err := ctx.Err(); err != nil {
+			setPackageErr(chunk, err)
+			continue
+		}
+		e.sendPackage(ctx, chunk)
+	}
+	return results, nil
+}
+
+// checkRequest проверяет запрос пакета так же, как Get: ValidateCreditRequest, если включена
+// проверка, и XSD-схему, если она задана. Схема описывает bki_request с одним запросом,
+// поэтому каждый запрос проверяется отдельным документом.
+func (e *equifaxCredit) checkRequest(r *CreditRequest) error {
+	if e.validate {
+		if err := ValidateCreditRequest(e.partnerID, r); err != nil {
+			return err
+		}
+	}
+	if e.schema == "" {
+		return nil
+	}
+
+	data, err := e.encode(bkiRequest{
+		Version:   EquifaxCreditVersion,
+		PartnerID: e.partnerID,
+		Requests:  []*CreditRequest{r},
+	})
+	if err != nil {
+		return err
+	}
+	return e.requestValidate(data)
+}
+
+func (e *equifaxCredit) sendPackage(ctx context.Context, chunk []*CreditPackageResult) {
+	req := bkiRequest{
+		Version:   EquifaxCreditVersion,
+		PartnerID: e.partnerID,
+		PackageID: newPackageID(),
+		RecCount:  strconv.Itoa(len(chunk)),
+		Requests:  make([]*CreditRequest, len(chunk)),
+	}
+	for i, res := range chunk {
+		req.Requests[i] = res.Request
+		res.Package = req.PackageID
+	}
+
+	data, err := e.encode(req)
+	if err != nil {
+		setPackageErr(chunk, err)
+		return
+	}
+
+	signed, err := e.signContext(ctx, data)
+	if err != nil {
+		setPackageErr(chunk, err)
+		return
+	}
+
+	if e.saveReq {
+		ioutil.WriteFile(req.PackageID+".sig", signed, 0755)
+	}
+
+	var (
+		resp      *creditPackageResponse
+		signature *SignatureInfo
+	)
+	// пакет из платных запросов отчетов не идемпотентен: повторяются только отказы, после
+	// которых бюро пакет не принимало
+	err = retry(ctx, e.retry, false, new(NullLogger), func() error {
+		var err error
+		resp, signature, err = e.postPackage(ctx, signed, chunk)
+		return err
+	})
+	if err != nil {
+		setPackageErr(chunk, err)
+		return
+	}
+
+	byNum := make(map[string]*Response, len(resp.Responses))
+	for _, r := range resp.Responses {
+		byNum[r.Num] = r
+	}
+	for _, res := range chunk {
+		res.Signature = signature
+		r, ok := byNum[strconv.Itoa(res.Request.Num)]
+		if !ok {
+			res.Err = errors.Wrapf(ErrResponseMissing, "num %d", res.Request.Num)
+			continue
+		}
+		res.Response = r
+		res.Err = checkResponseCode(r)
+	}
+}
+
+// postPackage отправляет пакет и разбирает ответ. Если ни один response не относится к
+// запросам пакета, а код ответа - ошибка (например, 11 или 15 на весь пакет), она
+// возвращается как ошибка пакета, чтобы ее обработала политика повторов.
+func (e *equifaxCredit) postPackage(ctx context.Context, signed []byte, chunk []*CreditPackageResult) (*creditPackageResponse, *SignatureInfo, error) {
+	content, signature, err := e.exchange(ctx, signed)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var resp *creditPackageResponse
+	dec := xml.NewDecoder(bytes.NewReader(content))
+	dec.CharsetReader = acharset.CharsetReader
+	if err = dec.Decode(&resp); err != nil {
+		return nil, nil, err
+	}
+
+	nums := make(map[string]bool, len(chunk))
+	for _, res := range chunk {
+		nums[strconv.Itoa(res.Request.Num)] = true
+	}
+	for _, r := range resp.Responses {
+		if nums[r.Num] {
+			return resp, signature, nil
+		}
+	}
+	if len(resp.Responses) > 0 {
+		if err := checkResponseCode(resp.Responses[0]); err != nil {
+			return nil, nil, err
+		}
+	}
+	return resp, signature, nil
+}
+
+func setPackageErr(chunk []*CreditPackageResult, err error) {
+	for _, res := range chunk {
+		res.Err = err
+	}
+}
@@ -0,0 +1,60 @@
+package equifax
+
+import "encoding/xml"
+
+// Разделы кредитного отчета формата 3.4. Разбираются только элементы, имена которых
+// совпадают со схемой запроса bki_request; остальное содержимое раздела доступно
+// в исходном виде в Data.
+
+type TitlePart struct {
+	XMLName     xml.Name           `xml:"title_part"`
+	Individual  *ReportIndividual  `xml:"private"`    // субъект КИ - физ. лицо
+	LegalEntity *ReportLegalEntity `xml:"commercial"` // субъект КИ - юр. лицо
+	Data        []byte             `xml:",innerxml"`
+}
+
+type ReportIndividual struct {
+	LastName   string           `xml:"lastname"`   // фамилия
+	FirstName  string           `xml:"firstname"`  // имя
+	MiddleName string           `xml:"middlename"` // отчество
+	Gender     Gender           `xml:"gender"`     // пол
+	Birthday   Date             `xml:"birthday"`   // дата рождения
+	Birthplace string           `xml:"birthplace"` // место рождения
+	Documents  []ReportDocument `xml:"doc"`        // документы, удостоверяющие личность (текущий и ранее выданные)
+	INN        string           `xml:"inn"`        // ИНН
+	PfrNO      string           `xml:"pfno"`       // СНИЛС
+}
+
+// ReportLegalEntity - титульная часть отчета по юр. лицу. Основная и дополнительная части
+// отчета по юр. лицу не разбираются и доступны только в BasePart.Data и AddPart.Data.
+type ReportLegalEntity struct {
+	FullName    string   `xml:"fullname"`    // полное наименование
+	ShortName   string   `xml:"shortname"`   // сокращенное наименование
+	FirmName    string   `xml:"firmname"`    // фирменное наименование
+	ForeignName string   `xml:"foreignname"` // наименование на языке народов РФ и (или) иностранном языке
+	Resident    Resident `xml:"resident"`    // признак резидентства
+	RegCountry  Country  `xml:"regcountry"`  // государство регистрации
+	Phone       string   `xml:"phone"`       // контактные телефоны
+	INN         string   `xml:"inn"`         // ИНН
+	EGRN        string   `xml:"egrn"`        // ОГРН
+}
+
+type ReportDocument struct {
+	DocType    DocType `xml:"doctype"`    // тип документа
+	DocNO      string  `xml:"docno"`      // серия и номер документа
+	DocDate    Date    `xml:"docdate"`    // дата выдачи документа
+	DocEndDate Date    `xml:"docenddate"` // дата окончания действия документа
+	DocPlace   string  `xml:"docplace"`   // место выдачи документа
+}
+
+type BasePart struct {
+	XMLName     xml.Name      `xml:"base_part"`
+	AddressReg  []AddressReg  `xml:"addr_reg"`  // адреса регистрации
+	AddressFact []AddressFact `xml:"addr_fact"` // адреса фактического места проживания
+	Data        []byte        `xml:",innerxml"`
+}
+
+type AddPart struct {
+	XMLName xml.Name `xml:"add_part"`
+	Data    []byte   `xml:",innerxml"`
+}
@@ -0,0 +1,356 @@
+package equifax
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// CreditFieldError - ошибка значения поля запроса кредитного отчета. Code - код, с которым
+// бюро отклонило бы запрос: ResponseCodeType12 для нарушений XSD-схемы, ResponseCodeType30,
+// 31, 32 и 37 для правил согласия, заявления и иной цели согласия.
+type CreditFieldError struct {
+	Field  string // путь к полю в запросе, например addr_reg.index
+	Code   ResponseCode
+	Reason string
+}
+
+func (e *CreditFieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Reason)
+}
+
+// Unwrap сопоставляет ошибку с ErrXXX кода ответа, как ResponseCodeError.
+func (e *CreditFieldError) Unwrap() error {
+	return (&ResponseCodeError{Code: e.Code}).Unwrap()
+}
+
+// CreditValidationError перечисляет все ошибки полей запроса кредитного отчета.
+type CreditValidationError []*CreditFieldError
+
+func (e CreditValidationError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return "equifax: invalid credit request: " + strings.Join(msgs, "; ")
+}
+
+// Code возвращает ResponseCodeType12, если запрос не соответствует схеме, иначе код первой ошибки:
+// бюро проверяет структуру запроса раньше правил согласия.
+func (e CreditValidationError) Code() ResponseCode {
+	for _, err := range e {
+		if err.Code == ResponseCodeType12 {
+			return ResponseCodeType12
+		}
+	}
+	if len(e) == 0 {
+		return ResponseCodeType0
+	}
+	return e[0].Code
+}
+
+// Unwrap возвращает ErrXXX для Code, поэтому errors.Is(err, ErrConsentMissing) работает и для
+// ошибки проверки запроса.
+func (e CreditValidationError) Unwrap() error {
+	return (&ResponseCodeError{Code: e.Code()}).Unwrap()
+}
+
+// Шаблоны допустимых символов из XSD-схемы запроса.
+var (
+	patternAlnum        = regexp.MustCompile(`^[a-zA-Z0-9]*$`)
+	patternCyrAlnum     = regexp.MustCompile(`^[a-zA-Zа-яА-ЯЁё0-9]*$`)
+	patternDigits       = regexp.MustCompile(`^[0-9]*$`)
+	patternFizName      = regexp.MustCompile(`^[a-zA-Zа-яА-ЯЁё0-9\- .']*$`)
+	patternFizMiddle    = regexp.MustCompile(`^[a-zA-Zа-яА-ЯЁё\- .']*$`)
+	patternFizPlace     = regexp.MustCompile(`^[a-zA-Zа-яА-ЯЁё0-9\- .,'\\/№():_+|"#;]*$`)
+	patternFizDocNo     = regexp.MustCompile(`^[a-zA-Zа-яА-ЯЁё0-9\-\\/;= ]*$`)
+	patternJurName      = regexp.MustCompile(`^[a-zA-Zа-яА-ЯЁё0-9\- .,'\\/№():_+|"#&%]*$`)
+	patternJurPhone     = regexp.MustCompile(`^[a-zA-Zа-яА-ЯЁё0-9\- .,'\\/№():_+|"#]*$`)
+	patternAddr         = regexp.MustCompile(`^[a-zA-Zа-яА-ЯЁё0-9\-.,'\\/№:()_|" ]*$`)
+	patternReasonText   = regexp.MustCompile(`^[a-zA-Zа-яА-ЯЁё0-9\- .,'\\/№():_+|"#&]*$`)
+	patternAreaText     = regexp.MustCompile(`^[a-zA-Zа-яА-ЯЁё0-9\-.,'\\/№:()_" ]*$`)
+	patternConsentOwner = regexp.MustCompile(`^[a-zA-Zа-яА-ЯЁё0-9\- .,'\\/№():_+|"#?!;&]*$`)
+)
+
+type creditValidator struct {
+	errs CreditValidationError
+}
+
+func (v *creditValidator) add(field string, code ResponseCode, format string, args ...interface{}) {
+	v.errs = append(v.errs, &CreditFieldError{Field: field, Code: code, Reason: fmt.Sprintf(format, args...)})
+}
+
+func (v *creditValidator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+// str проверяет строку по ограничениям XSD: длина от min до max символов и шаблон.
+// Пустое значение необязательного поля не проверяется.
+func (v *creditValidator) str(field string, value string, required bool, min, max int, pattern *regexp.Regexp) {
+	if value == "" {
+		if required {
+			v.add(field, ResponseCodeType12, "is required")
+		}
+		return
+	}
+	if n := utf8.RuneCountInString(value); n < min || n > max {
+		if min == max {
+			v.add(field, ResponseCodeType12, "length %d, must be %d", n, max)
+		} else {
+			v.add(field, ResponseCodeType12, "length %d, must be from %d to %d", n, min, max)
+		}
+		return
+	}
+	if !pattern.MatchString(value) {
+		v.add(field, ResponseCodeType12, "contains invalid characters")
+	}
+}
+
+func (v *creditValidator) date(field string, value Date) {
+	if value.IsZero() {
+		v.add(field, ResponseCodeType12, "is required")
+	}
+}
+
+func (v *creditValidator) oneOf(field string, value uint32, allowed ...uint32) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.add(field, ResponseCodeType12, "unknown value %d", value)
+}
+
+// enum проверяет значение справочника; нулевое значение необязательного поля допускается.
+func (v *creditValidator) enum(field string, value enum, optional bool) {
+	if optional && reflect.ValueOf(value).IsZero() {
+		return
+	}
+	if reflect.ValueOf(value).IsZero() {
+		v.add(field, ResponseCodeType12, "is required")
+		return
+	}
+	if !value.IsValid() {
+		v.add(field, ResponseCodeType12, "unknown value %v", value)
+	}
+}
+
+// ValidateCreditRequest проверяет запрос кредитного отчета вместе с кодом партнера, которым
+// клиент заполняет bki_request.
+func ValidateCreditRequest(partnerID string, r *CreditRequest) error {
+	v := new(creditValidator)
+	v.str("partnerid", partnerID, true, 3, 3, patternAlnum)
+	r.validate(v)
+	return v.err()
+}
+
+// Validate проверяет запрос до подписи по тем же ограничениям, что и XSD-схема бюро
+// (длины, шаблоны символов, справочники, обязательные блоки), и по правилам согласия,
+// которые бюро проверяет после схемы. Возвращает CreditValidationError со всеми найденными
+// ошибками. Код партнера проверяет ValidateCreditRequest.
+func (r *CreditRequest) Validate() error {
+	v := new(creditValidator)
+	r.validate(v)
+	return v.err()
+}
+
+func (r *CreditRequest) validate(v *creditValidator) {
+	if r.Num < 0 || len(strconv.Itoa(r.Num)) > 15 {
+		v.add("num", ResponseCodeType12, "must be from 0 to 15 digits")
+	}
+	v.str("type", r.Type, true, 1, 15, patternDigits)
+
+	switch {
+	case r.Individual == nil && r.LegalEntity == nil:
+		v.add("private", ResponseCodeType12, "private or commercial is required")
+	case r.Individual != nil && r.LegalEntity != nil:
+		v.add("private", ResponseCodeType12, "only one of private or commercial is allowed")
+	case r.Individual != nil:
+		r.Individual.validate(v)
+	default:
+		r.LegalEntity.validate(v)
+	}
+
+	v.oneOf("reason", uint32(r.Reason), 0, 1, 2, 3, 4, 5, 6, 9)
+	v.str("reason_text", r.ReasonText, false, 1, 1500, patternReasonText)
+	if r.Reason == ReasonType9 && r.ReasonText == "" {
+		v.add("reason_text", ResponseCodeType37, "is required for reason %d", r.Reason)
+	}
+
+	if r.Application == nil {
+		v.add("application", ResponseCodeType32, "is required")
+	} else {
+		r.Application.validate(v)
+		switch {
+		case r.LegalEntity != nil && r.Application.Individual != nil:
+			v.add("application.private", ResponseCodeType12, "is not allowed for commercial request")
+		case r.Individual != nil && r.Application.LegalEntity != nil:
+			v.add("application.commercial", ResponseCodeType12, "is not allowed for private request")
+		}
+	}
+
+	if r.AddressReg == nil {
+		v.add("addr_reg", ResponseCodeType12, "is required")
+	} else {
+		a := r.AddressReg
+		validateAddress(v, "addr_reg", a.Owner, a.Index, a.AddrTotal, a.Country, a.Region, a.City, a.District, a.Street, a.House, a.Flat)
+	}
+
+	if r.AddressFact == nil {
+		v.add("addr_fact", ResponseCodeType12, "is required")
+	} else {
+		a := r.AddressFact
+		validateAddress(v, "addr_fact", a.Owner, a.Index, a.AddrTotal, a.Country, a.Region, a.City, a.District, a.Street, a.House, a.Flat)
+	}
+}
+
+func (p *Individual) validate(v *creditValidator) {
+	v.str("private.lastname", p.LastName, true, 1, 50, patternFizName)
+	v.str("private.firstname", p.FirstName, true, 1, 50, patternFizName)
+	v.str("private.middlename", p.MiddleName, false, 1, 50, patternFizMiddle)
+	if p.Gender != 0 {
+		v.oneOf("private.gender", uint32(p.Gender), 1, 2, 9)
+	}
+	v.date("private.birthday", p.Birthday)
+	v.str("private.birthplace", p.Birthplace, true, 1, 255, patternFizPlace)
+
+	if d := p.IdentityDocument; d == nil {
+		v.add("private.doc", ResponseCodeType12, "is required")
+	} else {
+		if d.DocType < DocType1 || d.DocType > DocType14 {
+			v.add("private.doc.doctype", ResponseCodeType12, "unknown value %d", d.DocType)
+		}
+		v.str("private.doc.docno", d.DocNO, true, 1, 20, patternFizDocNo)
+		v.date("private.doc.docdate", d.DocDate)
+		if !d.DocEndDate.IsZero() && d.DocEndDate.Before(d.DocDate.Time) {
+			v.add("private.doc.docenddate", ResponseCodeType12, "must not be before docdate")
+		}
+		v.str("private.doc.docplace", d.DocPlace, true, 1, 255, patternFizPlace)
+	}
+
+	v.str("private.inn", p.INN, false, 11, 12, patternDigits)
+	v.str("private.pfno", p.PfrNO, false, 11, 11, patternDigits)
+}
+
+// validate проверяет титульную часть юр. лица. Резидент регистрируется в России и
+// идентифицируется по ИНН (10 цифр) и ОГРН (13 цифр) с контрольными цифрами - без них бюро
+// отвечает ResponseCodeType34. Нерезидент регистрируется в другом государстве, его
+// регистрационные номера проверяются только по схеме.
+func (c *LegalEntity) validate(v *creditValidator) {
+	v.str("commercial.fullname", c.FullName, true, 1, 255, patternJurName)
+	v.str("commercial.shortname", c.ShortName, false, 1, 120, patternJurName)
+	v.str("commercial.firmname", c.FirmName, false, 1, 120, patternJurName)
+	v.str("commercial.foreignname", c.ForeignName, false, 1, 255, patternJurName)
+	v.oneOf("commercial.resident", uint32(c.Resident), 0, 1)
+	v.str("commercial.regcountry", string(c.RegCountry), true, 2, 2, patternAlnum)
+	v.str("commercial.phone", c.Phone, true, 1, 150, patternJurPhone)
+	v.str("commercial.inn", c.INN, false, 1, 20, patternCyrAlnum)
+	v.str("commercial.egrn", c.EGRN, false, 1, 20, patternCyrAlnum)
+
+	switch c.Resident {
+	case ResidentType1:
+		if c.RegCountry != "" && c.RegCountry != CountryTypeRU {
+			v.add("commercial.regcountry", ResponseCodeType12, "must be %s for resident", CountryTypeRU)
+		}
+		switch {
+		case c.INN == "":
+			v.add("commercial.inn", ResponseCodeType34, "is required for resident")
+		case len(c.INN) != 10 || !isDigits(c.INN):
+			v.add("commercial.inn", ResponseCodeType34, "must contain 10 digits for resident")
+		case !validINN(c.INN):
+			v.add("commercial.inn", ResponseCodeType34, "invalid checksum")
+		}
+		switch {
+		case c.EGRN == "":
+			v.add("commercial.egrn", ResponseCodeType34, "is required for resident")
+		case len(c.EGRN) != 13 || !isDigits(c.EGRN):
+			v.add("commercial.egrn", ResponseCodeType34, "must contain 13 digits for resident")
+		case !validOGRN(c.EGRN):
+			v.add("commercial.egrn", ResponseCodeType34, "invalid checksum")
+		}
+	case ResidentType0:
+		switch {
+		case c.RegCountry == CountryTypeRU:
+			v.add("commercial.regcountry", ResponseCodeType12, "must not be %s for non-resident", CountryTypeRU)
+		case c.RegCountry != "" && !c.RegCountry.IsValid():
+			v.add("commercial.regcountry", ResponseCodeType12, "unknown value %s", c.RegCountry)
+		}
+	}
+}
+
+// validate проверяет блок заявления юр. лица.
+func (c *ApplicationLegalEntity) validate(v *creditValidator) {
+	v.oneOf("application.commercial.company_state", uint32(c.State), 0, 1, 9)
+	v.oneOf("application.commercial.company_size", uint32(c.Size), 0, 1, 2, 3, 4, 9)
+	v.enum("application.commercial.company_area", c.Area, false)
+	v.str("application.commercial.company_area_text", c.AreaText, c.Area == CompanyAreaType98, 1, 100, patternAreaText)
+
+	switch {
+	case c.BeginningDate.IsZero():
+		v.add("application.commercial.company_beginning_date", ResponseCodeType12, "is required")
+	case c.BeginningDate.After(time.Now()):
+		v.add("application.commercial.company_beginning_date", ResponseCodeType12, "must not be in the future")
+	}
+}
+
+// validate проверяет правила согласия: без согласия субъекта и информирования пользователя
+// бюро отвечает ResponseCodeType30, при неверной дате согласия - ResponseCodeType31.
+func (a *Application) validate(v *creditValidator) {
+	v.oneOf("application.consent", uint32(a.Consent), 0, 1)
+	if a.Consent == ConsentType0 {
+		v.add("application.consent", ResponseCodeType30, "consent is not given")
+	}
+	v.oneOf("application.admcode_inform", uint32(a.AdmCodeInForm), 0, 1)
+	if a.AdmCodeInForm == AdmCodeInFormType0 {
+		v.add("application.admcode_inform", ResponseCodeType30, "user is not informed")
+	}
+
+	switch {
+	case a.ConsentDate.IsZero():
+		v.add("application.consentdate", ResponseCodeType31, "is required")
+	case a.ConsentDate.After(time.Now()):
+		v.add("application.consentdate", ResponseCodeType31, "must not be in the future")
+	case !a.ConsentEndDate.IsZero() && a.ConsentEndDate.Before(a.ConsentDate.Time):
+		v.add("application.consentenddate", ResponseCodeType31, "must not be before consentdate")
+	}
+
+	v.str("application.consent_owner", a.ConsentOwner, false, 1, 3270, patternConsentOwner)
+
+	if a.Individual != nil && a.LegalEntity != nil {
+		v.add("application.private", ResponseCodeType12, "only one of private or commercial is allowed")
+	}
+	if a.LegalEntity != nil {
+		a.LegalEntity.validate(v)
+	}
+}
+
+// validateAddress проверяет адрес: индекс обязателен, далее либо адрес одной строкой
+// ({field}_total), либо страна, регион и населенный пункт с необязательными районом,
+// улицей, домом и квартирой.
+func validateAddress(v *creditValidator, field string, owner AddressOwner, index, total string, country Country, region Region, city, district, street, house, flat string) {
+	v.oneOf(field+".owner", uint32(owner), 0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+	v.str(field+".index", index, true, 6, 6, patternDigits)
+
+	if total != "" {
+		v.str(field+"."+field+"_total", total, true, 1, 500, patternAddr)
+		if country != "" || region != "" || city != "" || district != "" || street != "" || house != "" || flat != "" {
+			v.add(field+"."+field+"_total", ResponseCodeType12, "must not be combined with country, region, city and street")
+		}
+		return
+	}
+
+	v.str(field+".country", string(country), true, 2, 2, patternAlnum)
+	v.str(field+".region", string(region), true, 2, 2, patternDigits)
+	v.str(field+".city", city, true, 1, 100, patternAddr)
+	v.str(field+".district", district, false, 1, 100, patternAddr)
+	v.str(field+".street", street, false, 1, 100, patternAddr)
+	v.str(field+".house", house, false, 1, 100, patternAddr)
+	v.str(field+".flat", flat, false, 1, 50, patternAddr)
+}
@@ -0,0 +1,227 @@
+package equifax
+
+// Допустимые значения справочников из const.go для проверки запросов FPS.
+
+func (v DocType) IsValid() bool {
+	switch v {
+	case DocType1, DocType2, DocType3, DocType4, DocType5, DocType6, DocType7, DocType8, DocType9, DocType10,
+		DocType11, DocType12, DocType13, DocType14, DocType15, DocType98, DocType99:
+		return true
+	}
+	return false
+}
+
+func (v Sex) IsValid() bool {
+	switch v {
+	case SexType1, SexType2, SexType99:
+		return true
+	}
+	return false
+}
+
+func (v Country) IsValid() bool {
+	switch v {
+	case CountryTypeAU, CountryTypeAT, CountryTypeAZ, CountryTypeAX, CountryTypeAL, CountryTypeDZ,
+		CountryTypeAS, CountryTypeAI, CountryTypeAO, CountryTypeAD, CountryTypeAG, CountryTypeAR, CountryTypeAM,
+		CountryTypeAW, CountryTypeAF, CountryTypeBS, CountryTypeBD, CountryTypeBB, CountryTypeBH, CountryTypeBY,
+		CountryTypeBZ, CountryTypeBE, CountryTypeBJ, CountryTypeBM, CountryTypeBG, CountryTypeBO, CountryTypeBA,
+		CountryTypeBW, CountryTypeBR, CountryTypeIO, CountryTypeBN, CountryTypeBV, CountryTypeBF, CountryTypeBI,
+		CountryTypeBT, CountryTypeVU, CountryTypeVA, CountryTypeGB, CountryTypeHU, CountryTypeVE, CountryTypeVG,
+		CountryTypeVI, CountryTypeUM, CountryTypeTL, CountryTypeVN, CountryTypeGA, CountryTypeGY, CountryTypeHT,
+		CountryTypeGM, CountryTypeGH, CountryTypeGP, CountryTypeGT, CountryTypeGF, CountryTypeGN, CountryTypeGW,
+		CountryTypeDE, CountryTypeGG, CountryTypeGI, CountryTypeHN, CountryTypeHK, CountryTypeGD, CountryTypeGL,
+		CountryTypeGR, CountryTypeGE, CountryTypeGU, CountryTypeDK, CountryTypeJE, CountryTypeDJ, CountryTypeDM,
+		CountryTypeDO, CountryTypeEG, CountryTypeZM, CountryTypeEH, CountryTypeZW, CountryTypeYE, CountryTypeIL,
+		CountryTypeIN, CountryTypeID, CountryTypeJO, CountryTypeIQ, CountryTypeIR, CountryTypeIE, CountryTypeIS,
+		CountryTypeES, CountryTypeIT, CountryTypeCV, CountryTypeKZ, CountryTypeKY, CountryTypeKH, CountryTypeCM,
+		CountryTypeCA, CountryTypeQA, CountryTypeKE, CountryTypeCY, CountryTypeKG, CountryTypeKI, CountryTypeCN,
+		CountryTypeKP, CountryTypeCC, CountryTypeCO, CountryTypeKM, CountryTypeCD, CountryTypeCG, CountryTypeCR,
+		CountryTypeCI, CountryTypeCU, CountryTypeKW, CountryTypeCK, CountryTypeLA, CountryTypeLV, CountryTypeLS,
+		CountryTypeLR, CountryTypeLB, CountryTypeLY, CountryTypeLT, CountryTypeLI, CountryTypeLU, CountryTypeMU,
+		CountryTypeMR, CountryTypeMG, CountryTypeYT, CountryTypeMO, CountryTypeMW, CountryTypeMY, CountryTypeML,
+		CountryTypeMV, CountryTypeMT, CountryTypeMA, CountryTypeMQ, CountryTypeMH, CountryTypeMX, CountryTypeMZ,
+		CountryTypeMD, CountryTypeMC, CountryTypeMN, CountryTypeMS, CountryTypeMM, CountryTypeNA, CountryTypeNR,
+		CountryTypeNP, CountryTypeNE, CountryTypeNG, CountryTypeAN, CountryTypeNL, CountryTypeNI, CountryTypeNU,
+		CountryTypeNZ, CountryTypeNC, CountryTypeNO, CountryTypeNF, CountryTypeAE, CountryTypeOM, CountryTypeIM,
+		CountryTypeHM, CountryTypePK, CountryTypePW, CountryTypePS, CountryTypePA, CountryTypePG, CountryTypePY,
+		CountryTypePE, CountryTypePN, CountryTypePL, CountryTypePT, CountryTypePR, CountryTypeKR, CountryTypeMK,
+		CountryTypeRE, CountryTypeCX, CountryTypeRU, CountryTypeRW, CountryTypeRO, CountryTypeSV, CountryTypeWS,
+		CountryTypeSM, CountryTypeST, CountryTypeSA, CountryTypeSZ, CountryTypeMF, CountryTypeSH, CountryTypeMP,
+		CountryTypeSC, CountryTypeBL, CountryTypeSN, CountryTypePM, CountryTypeVC, CountryTypeKN, CountryTypeLC,
+		CountryTypeRS, CountryTypeSG, CountryTypeSY, CountryTypeSK, CountryTypeSI, CountryTypeSB, CountryTypeSO,
+		CountryTypeSD, CountryTypeSR, CountryTypeUS, CountryTypeSL, CountryTypeTJ, CountryTypeTW, CountryTypeTH,
+		CountryTypeTZ, CountryTypeTC, CountryTypeTG, CountryTypeTK, CountryTypeTO, CountryTypeTT, CountryTypeTV,
+		CountryTypeTN, CountryTypeTM, CountryTypeTR, CountryTypeUG, CountryTypeUZ, CountryTypeUA, CountryTypeWF,
+		CountryTypeUY, CountryTypeFO, CountryTypeFM, CountryTypeFJ, CountryTypePH, CountryTypeFI, CountryTypeFK,
+		CountryTypeFR, CountryTypePF, CountryTypeTF, CountryTypeHR, CountryTypeCF, CountryTypeTD, CountryTypeME,
+		CountryTypeCZ, CountryTypeCL, CountryTypeCH, CountryTypeSE, CountryTypeLK, CountryTypeEC, CountryTypeGQ,
+		CountryTypeER, CountryTypeEE, CountryTypeET, CountryTypeGS, CountryTypeZA, CountryTypeJM, CountryTypeJP,
+		CountryType98, CountryType99:
+		return true
+	}
+	return false
+}
+
+func (v Education) IsValid() bool {
+	switch v {
+	case EducationType0, EducationType1, EducationType2, EducationType3, EducationType4, EducationType5,
+		EducationType6, EducationType8, EducationType9, EducationType99:
+		return true
+	}
+	return false
+}
+
+func (v Marital) IsValid() bool {
+	switch v {
+	case MaritalType0, MaritalType1, MaritalType2, MaritalType3, MaritalType4, MaritalType9, MaritalType99:
+		return true
+	}
+	return false
+}
+
+func (v EmployerSize) IsValid() bool {
+	switch v {
+	case EmployerSizeType0, EmployerSizeType1, EmployerSizeType2, EmployerSizeType3, EmployerSizeType4,
+		EmployerSizeType9, EmployerSizeType99:
+		return true
+	}
+	return false
+}
+
+func (v BusinessIndustry) IsValid() bool {
+	switch v {
+	case BusinessIndustryType0, BusinessIndustryType1, BusinessIndustryType2, BusinessIndustryType3,
+		BusinessIndustryType4, BusinessIndustryType5, BusinessIndustryType6, BusinessIndustryType7,
+		BusinessIndustryType8, BusinessIndustryType9, BusinessIndustryType10, BusinessIndustryType11,
+		BusinessIndustryType12, BusinessIndustryType13, BusinessIndustryType14, BusinessIndustryType15,
+		BusinessIndustryType16, BusinessIndustryType17, BusinessIndustryType18, BusinessIndustryType19,
+		BusinessIndustryType20, BusinessIndustryType21, BusinessIndustryType22, BusinessIndustryType23,
+		BusinessIndustryType24, BusinessIndustryType25, BusinessIndustryType26, BusinessIndustryType27,
+		BusinessIndustryType28, BusinessIndustryType29, BusinessIndustryType30, BusinessIndustryType31,
+		BusinessIndustryType32, BusinessIndustryType33, BusinessIndustryType34, BusinessIndustryType35,
+		BusinessIndustryType36, BusinessIndustryType37, BusinessIndustryType38, BusinessIndustryType39,
+		BusinessIndustryType40, BusinessIndustryType41, BusinessIndustryType42, BusinessIndustryType97,
+		BusinessIndustryType98, BusinessIndustryType99:
+		return true
+	}
+	return false
+}
+
+func (v IncomeProof) IsValid() bool {
+	switch v {
+	case IncomeProofType1, IncomeProofType2, IncomeProofType3, IncomeProofType4, IncomeProofType5,
+		IncomeProofType97, IncomeProofType98, IncomeProofType99:
+		return true
+	}
+	return false
+}
+
+func (v ProductType) IsValid() bool {
+	switch v {
+	case ProductTypeType0, ProductTypeType1, ProductTypeType2, ProductTypeType3, ProductTypeType4,
+		ProductTypeType5, ProductTypeType6, ProductTypeType7, ProductTypeType8, ProductTypeType9,
+		ProductTypeType10, ProductTypeType11, ProductTypeType12, ProductTypeType13, ProductTypeType14,
+		ProductTypeType15, ProductTypeType16, ProductTypeType17, ProductTypeType18, ProductTypeType19,
+		ProductTypeType20, ProductTypeType21, ProductTypeType99:
+		return true
+	}
+	return false
+}
+
+func (v OriginalChannel) IsValid() bool {
+	switch v {
+	case OriginalChannelType1, OriginalChannelType2, OriginalChannelType3, OriginalChannelType4,
+		OriginalChannelType5, OriginalChannelType6, OriginalChannelType7, OriginalChannelType98,
+		OriginalChannelType99:
+		return true
+	}
+	return false
+}
+
+func (v SumCurrency) IsValid() bool {
+	switch v {
+	case SumCurrencyType840, SumCurrencyTypeUSD, SumCurrencyType810, SumCurrencyTypeRUR, SumCurrencyTypeRUB,
+		SumCurrencyType978, SumCurrencyTypeEUR, SumCurrencyType756, SumCurrencyTypeCHF, SumCurrencyType392,
+		SumCurrencyTypeJPY:
+		return true
+	}
+	return false
+}
+
+func (v CollateralExistence) IsValid() bool {
+	switch v {
+	case CollateralExistenceType0, CollateralExistenceType1, CollateralExistenceType99:
+		return true
+	}
+	return false
+}
+
+func (v PurchaseExistence) IsValid() bool {
+	switch v {
+	case PurchaseExistenceType0, PurchaseExistenceType1, PurchaseExistenceType99:
+		return true
+	}
+	return false
+}
+
+func (v NewApplicant) IsValid() bool {
+	switch v {
+	case NewApplicantType0, NewApplicantType1, NewApplicantType9, NewApplicantType99:
+		return true
+	}
+	return false
+}
+
+func (v ApplicantType) IsValid() bool {
+	switch v {
+	case ApplicantTypeType1, ApplicantTypeType2, ApplicantTypeType3:
+		return true
+	}
+	return false
+}
+
+func (v ResponseIsNeeded) IsValid() bool {
+	switch v {
+	case ResponseIsNeededType0, ResponseIsNeededType1, ResponseIsNeededType3:
+		return true
+	}
+	return false
+}
+
+func (v ApplicationStatus) IsValid() bool {
+	switch v {
+	case ApplicationStatusType1, ApplicationStatusType2, ApplicationStatusType3, ApplicationStatusType4,
+		ApplicationStatusType5, ApplicationStatusType8, ApplicationStatusType9:
+		return true
+	}
+	return false
+}
+
+func (v ApplicationFraudStatus) IsValid() bool {
+	switch v {
+	case ApplicationFraudStatusType1, ApplicationFraudStatusType2, ApplicationFraudStatusType3,
+		ApplicationFraudStatusType4, ApplicationFraudStatusType8, ApplicationFraudStatusType9:
+		return true
+	}
+	return false
+}
+
+func (v DefaultStatus) IsValid() bool {
+	switch v {
+	case DefaultStatusType1, DefaultStatusType2, DefaultStatusType3, DefaultStatusType8, DefaultStatusType9:
+		return true
+	}
+	return false
+}
+
+func (v CompanyArea) IsValid() bool {
+	switch v {
+	case CompanyAreaType00, CompanyAreaType01, CompanyAreaType02, CompanyAreaType03, CompanyAreaType04, CompanyAreaType05,
+		CompanyAreaType06, CompanyAreaType07, CompanyAreaType08, CompanyAreaType09, CompanyAreaType10, CompanyAreaType11,
+		CompanyAreaType12, CompanyAreaType13, CompanyAreaType14, CompanyAreaType15, CompanyAreaType16, CompanyAreaType17,
+		CompanyAreaType18, CompanyAreaType19, CompanyAreaType20, CompanyAreaType21, CompanyAreaType22, CompanyAreaType23,
+		CompanyAreaType24, CompanyAreaType25, CompanyAreaType98, CompanyAreaType99:
+		return true
+	}
+	return false
+}
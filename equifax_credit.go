@@ -2,16 +2,13 @@ package equifax
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
-	"io"
 	"io/ioutil"
 	"net/http"
 	"time"
 
 	"github.com/l-vitaly/acharset"
-	"github.com/l-vitaly/cryptopro"
-	"github.com/lestrrat/go-libxml2/parser"
-	"github.com/lestrrat/go-libxml2/xsd"
 	"github.com/pkg/errors"
 	"golang.org/x/text/encoding/charmap"
 )
@@ -21,13 +18,33 @@ const EquifaxCreditVersion = "3.4"
 var (
 	ErrInvalidRequest     = errors.New("invalid request")
 	ErrInvalidCertificate = errors.New("invalid certificate")
+	ErrSchemaUnsupported  = errors.New("xsd validation is not available without libxml2") // сборка с тегом nolibxml2
+
+	ErrReportTypeNotFound   = errors.New("report type not found")                     // 4
+	ErrPartnerNotFound      = errors.New("partner not found")                         // 5
+	ErrSignatureMismatch    = errors.New("request signature does not match partner")  // 11
+	ErrInvalidRequestXML    = errors.New("invalid request xml structure")             // 12
+	ErrInvalidVersion       = errors.New("invalid request version")                   // 15
+	ErrRequestNotSigned     = errors.New("request is not signed")                     // 19
+	ErrNoHistoryOnDate      = errors.New("credit history did not exist on that date") // 24
+	ErrConsentMissing       = errors.New("consent or admcode_inform is not given")    // 30
+	ErrInvalidConsentDate   = errors.New("invalid consent date")                      // 31
+	ErrApplicationMissing   = errors.New("application block is missing")              // 32
+	ErrInvalidReason        = errors.New("invalid reason or report type")             // 33
+	ErrLegalEntityIDMissing = errors.New("resident legal entity has no inn or ogrn")  // 34
+	ErrPfrNoMissing         = errors.New("pfno is missing")                           // 36
+	ErrReasonTextMissing    = errors.New("reason_text is missing")                    // 37
+	ErrServiceUnavailable   = errors.New("service unavailable")                       // 99
+	ErrUnknownResponseCode  = errors.New("unknown response code")
 )
 
 type bkiRequest struct {
 	XMLName   xml.Name `xml:"bki_request"`
 	Version   string   `xml:"version,attr"`
-	PartnerID string   `xml:"partnerid,attr"` // код партнера
-	Request   *CreditRequest
+	PartnerID string   `xml:"partnerid,attr"`           // код партнера
+	PackageID string   `xml:"packageid,attr,omitempty"` // идентификатор пакета, только в пакетном режиме
+	RecCount  string   `xml:"reccount,attr,omitempty"`  // количество запросов в пакете
+	Requests  []*CreditRequest
 }
 
 type AddressReg struct {
@@ -35,13 +52,13 @@ type AddressReg struct {
 	Owner     AddressOwner `xml:"owner"`                    // статус регистрации по данному адресу
 	Index     string       `xml:"index"`                    // индекс
 	AddrTotal string       `xml:"addr_reg_total,omitempty"` // адрес регистрации одной строкой
-	Country   Country      `xml:"country"`                  // страна
-	Region    Region       `xml:"region"`                   // код региона
-	City      string       `xml:"city"`                     // населенный пункт
+	Country   Country      `xml:"country,omitempty"`        // страна
+	Region    Region       `xml:"region,omitempty"`         // код региона
+	City      string       `xml:"city,omitempty"`           // населенный пункт
 	District  string       `xml:"district,omitempty"`       // район
-	Street    string       `xml:"street"`                   // улица
-	House     string       `xml:"house"`                    // дом/блок/строение
-	Flat      string       `xml:"flat"`                     // квартира/офис/комната
+	Street    string       `xml:"street,omitempty"`         // улица
+	House     string       `xml:"house,omitempty"`          // дом/блок/строение
+	Flat      string       `xml:"flat,omitempty"`           // квартира/офис/комната
 }
 
 type AddressFact struct {
@@ -49,13 +66,13 @@ type AddressFact struct {
 	Owner     AddressOwner `xml:"owner"`                     // статус регистрации по данному адресу
 	Index     string       `xml:"index"`                     // индекс
 	AddrTotal string       `xml:"addr_fact_total,omitempty"` // адрес фактического местонахождения одной строкой
-	Country   Country      `xml:"country"`                   // страна
-	Region    Region       `xml:"region"`                    // код региона
-	City      string       `xml:"city"`                      // населенный пункт
+	Country   Country      `xml:"country,omitempty"`         // страна
+	Region    Region       `xml:"region,omitempty"`          // код региона
+	City      string       `xml:"city,omitempty"`            // населенный пункт
 	District  string       `xml:"district,omitempty"`        // район
-	Street    string       `xml:"street"`                    // улица
-	House     string       `xml:"house"`                     // дом/блок/строение
-	Flat      string       `xml:"flat"`                      // квартира/офис/комната
+	Street    string       `xml:"street,omitempty"`          // улица
+	House     string       `xml:"house,omitempty"`           // дом/блок/строение
+	Flat      string       `xml:"flat,omitempty"`            // квартира/офис/комната
 }
 
 type EmploymentCompany struct {
@@ -151,12 +168,12 @@ type IdentityDocument struct {
 
 type Individual struct {
 	XMLName          xml.Name          `xml:"private"`
-	LastName         string            `xml:"lastname"`         // имя
-	FirstName        string            `xml:"firstname"`        // фамилия
-	MiddleName       string            `xml:"middlename"`       // отчество
-	Gender           Gender            `xml:"gender,omitempty"` // пол
-	Birthday         Date              `xml:"birthday"`         // дата рождения
-	Birthplace       string            `xml:"birthplace"`       // место рождения
+	LastName         string            `xml:"lastname"`             // имя
+	FirstName        string            `xml:"firstname"`            // фамилия
+	MiddleName       string            `xml:"middlename,omitempty"` // отчество
+	Gender           Gender            `xml:"gender,omitempty"`     // пол
+	Birthday         Date              `xml:"birthday"`             // дата рождения
+	Birthplace       string            `xml:"birthplace"`           // место рождения
 	IdentityDocument *IdentityDocument // документ удостоверяющий личность
 	INN              string            `xml:"inn,omitempty"`  // ИНН
 	PfrNO            string            `xml:"pfno,omitempty"` // СНИЛС
@@ -176,21 +193,6 @@ type CreditRequest struct {
 	Type         string       `xml:"type"`                  // идентификатор отчета
 }
 
-type TitlePart struct {
-	XMLName xml.Name `xml:"title_part"`
-	Data    []byte   `xml:",innerxml"`
-}
-
-type BasePart struct {
-	XMLName xml.Name `xml:"base_part"`
-	Data    []byte   `xml:",innerxml"`
-}
-
-type AddPart struct {
-	XMLName xml.Name `xml:"add_part"`
-	Data    []byte   `xml:",innerxml"`
-}
-
 type InformationParts struct {
 	XMLName xml.Name `xml:"information_parts"`
 	Data    []byte   `xml:",innerxml"`
@@ -213,140 +215,264 @@ type CreditResponse struct {
 	PartnerID string    `xml:"partnerid,attr"` // код партнера
 	DateTime  string    `xml:"datetime,attr"`
 	Response  *Response `xml:""`
+
+	Signature *SignatureInfo `xml:"-"` // результат проверки подписи ответа
 }
 
 type EquifaxCredit interface {
 	Get(r *CreditRequest) (*CreditResponse, error)
 }
 
+// EquifaxCreditContext - вызовы кредитного бюро с context.Context.
+type EquifaxCreditContext interface {
+	// GetContext - Get с поддержкой отмены и дедлайнов ctx как на этапе подписи, так и на этапе HTTP-запроса.
+	GetContext(ctx context.Context, r *CreditRequest) (*CreditResponse, error)
+}
+
+// EquifaxCreditConfig - настройка клиента кредитного бюро.
+type EquifaxCreditConfig interface {
+	// SetHTTPClient задает HTTP-клиент для обращения к бюро (таймауты, транспорт, прокси).
+	SetHTTPClient(client *http.Client)
+	SetRetryPolicy(policy RetryPolicy)
+	// SetVerifier задает проверку подписи ответов, как правило с сертификатом Equifax.
+	SetVerifier(verifier Verifier)
+	// SetInsecureAcceptUnverified разрешает принимать неподписанные ответы и ответы с неподтвержденной
+	// подписью; результат проверки остается в CreditResponse.Signature. По умолчанию такие ответы
+	// отклоняются с ErrResponseNotSigned, ErrUntrustedSigner или ErrInvalidSignature.
+	// Только для отладки и тестовых стендов бюро.
+	SetInsecureAcceptUnverified(accept bool)
+	// SetValidation включает проверку запроса ValidateCreditRequest перед подписью: запрос
+	// с ошибками не отправляется, возвращается CreditValidationError.
+	SetValidation(validate bool)
+}
+
+// CreditClient - клиент кредитного бюро, который возвращают конструкторы. EquifaxCredit
+// остается интерфейсом из одного Get, поэтому его сторонние реализации не ломаются
+// при добавлении новых вызовов и настроек.
+type CreditClient interface {
+	EquifaxCredit
+	EquifaxCreditContext
+	EquifaxCreditConfig
+}
+
 type equifaxCredit struct {
-	url       string
-	partnerID string
-	crt       cryptopro.Cert
-	schema    string
-	saveReq   bool
+	url        string
+	partnerID  string
+	signer     Signer
+	verifier   Verifier
+	schema     string
+	saveReq    bool
+	httpClient *http.Client
+	retry      RetryPolicy
+	insecure   bool
+	validate   bool
 }
 
-func NewEquifaxCredit(url string, partnerID string, crt cryptopro.Cert, schema string, saveReq bool) EquifaxCredit {
+// NewEquifaxCreditSigner создает клиент кредитного бюро, который подписывает запросы signer
+// и проверяет подпись ответов verifier. Без verifier ответы бюро не принимаются.
+func NewEquifaxCreditSigner(url string, partnerID string, signer Signer, verifier Verifier, schema string, saveReq bool) CreditClient {
 	return &equifaxCredit{
-		url:       url,
-		partnerID: partnerID,
-		crt:       crt,
-		schema:    schema,
-		saveReq:   saveReq,
+		url:        url,
+		partnerID:  partnerID,
+		signer:     signer,
+		verifier:   verifier,
+		schema:     schema,
+		saveReq:    saveReq,
+		httpClient: http.DefaultClient,
+		retry:      NoRetry,
 	}
 }
 
-func (e *equifaxCredit) requestValidate(reqBytes []byte) error {
-	p := parser.New()
-	doc, err := p.ParseReader(bytes.NewBuffer(reqBytes))
-	if err != nil {
-		return err
+func (e *equifaxCredit) SetHTTPClient(client *http.Client) {
+	if client == nil {
+		client = http.DefaultClient
 	}
-	defer doc.Free()
+	e.httpClient = client
+}
 
-	xsdSchema, err := ioutil.ReadFile(e.schema)
-	if err != nil {
-		return err
-	}
+func (e *equifaxCredit) SetVerifier(verifier Verifier) {
+	e.verifier = verifier
+}
 
-	sc, err := xsd.Parse(xsdSchema)
-	if err != nil {
-		return err
-	}
-	defer sc.Free()
+func (e *equifaxCredit) SetInsecureAcceptUnverified(accept bool) {
+	e.insecure = accept
+}
 
-	if err := sc.Validate(doc); err != nil {
-		var errorMsg string
-		for _, e := range err.(xsd.SchemaValidationError).Errors() {
-			errorMsg += e.Error() + "/n"
-		}
-		return errors.New(errorMsg)
+func (e *equifaxCredit) SetValidation(validate bool) {
+	e.validate = validate
+}
+
+func (e *equifaxCredit) SetRetryPolicy(policy RetryPolicy) {
+	if policy == nil {
+		policy = NoRetry
 	}
-	return nil
+	e.retry = policy
+}
+
+func (e *equifaxCredit) requestValidate(reqBytes []byte) error {
+	return ValidateSchema(e.schema, reqBytes)
 }
 
 func (e *equifaxCredit) Get(r *CreditRequest) (*CreditResponse, error) {
-	req := bkiRequest{
+	return e.GetContext(context.Background(), r)
+}
+
+func (e *equifaxCredit) GetContext(ctx context.Context, r *CreditRequest) (*CreditResponse, error) {
+	if e.validate {
+		if err := ValidateCreditRequest(e.partnerID, r); err != nil {
+			return nil, err
+		}
+	}
+
+	reqEncBytes, err := e.encode(bkiRequest{
 		Version:   EquifaxCreditVersion,
 		PartnerID: e.partnerID,
-		Request:   r,
+		Requests:  []*CreditRequest{r},
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	reqBuf := bytes.NewBuffer([]byte{})
-	reqBuf.WriteString(`<?xml version="1.0" encoding="windows-1251"?>` + "\n")
+	if e.schema != "" {
+		err = e.requestValidate(reqEncBytes)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	err := xml.NewEncoder(reqBuf).Encode(req)
+	reqBytes, err := e.signContext(ctx, reqEncBytes)
 	if err != nil {
 		return nil, err
 	}
 
-	reqEncBytes, err := charmap.Windows1251.NewEncoder().Bytes(reqBuf.Bytes())
+	if e.saveReq {
+		ioutil.WriteFile(time.Now().Format("20060102150405")+".sig", reqBytes, 0755)
+	}
+
+	var result *CreditResponse
+	err = retry(ctx, e.retry, true, new(NullLogger), func() error {
+		var err error
+		result, err = e.post(ctx, reqBytes)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	reqBuf = bytes.NewBuffer(reqEncBytes)
+	return result, nil
+}
 
-    if e.schema != "" {
-        err = e.requestValidate(reqBuf.Bytes())
-        if err != nil {
-            return nil, err
-        }
-    }
+// encode формирует документ bki_request в кодировке windows-1251.
+func (e *equifaxCredit) encode(req bkiRequest) ([]byte, error) {
+	reqBuf := bytes.NewBuffer([]byte{})
+	reqBuf.WriteString(`<?xml version="1.0" encoding="windows-1251"?>` + "\n")
 
-	dest := new(bytes.Buffer)
+	if err := xml.NewEncoder(reqBuf).Encode(req); err != nil {
+		return nil, err
+	}
+	return charmap.Windows1251.NewEncoder().Bytes(reqBuf.Bytes())
+}
 
-	msg, err := cryptopro.OpenToEncode(dest, cryptopro.EncodeOptions{
-		Signers: []cryptopro.Cert{e.crt},
-	})
+func (e *equifaxCredit) post(ctx context.Context, reqBytes []byte) (*CreditResponse, error) {
+	content, signature, err := e.exchange(ctx, reqBytes)
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = reqBuf.WriteTo(msg)
+	var result *CreditResponse
+	dec := xml.NewDecoder(bytes.NewReader(content))
+	dec.CharsetReader = acharset.CharsetReader
+	err = dec.Decode(&result)
+
 	if err != nil {
 		return nil, err
 	}
+	result.Signature = signature
 
-	msg.Close()
-
-	reqBytes := dest.Bytes()
+	if err = checkResponseCode(result.Response); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
 
-	if e.saveReq {
-		ioutil.WriteFile(time.Now().Format("20060102150405")+".sig", reqBytes, 0755)
+// exchange отправляет подписанный документ бюро и возвращает ответ с результатом проверки подписи.
+func (e *equifaxCredit) exchange(ctx context.Context, reqBytes []byte) ([]byte, *SignatureInfo, error) {
+	httpReq, err := http.NewRequest("POST", e.url, bytes.NewReader(reqBytes))
+	if err != nil {
+		return nil, nil, err
 	}
+	httpReq.Header.Set("Content-Type", "application/octet-stream")
 
-	resp, err := http.Post(e.url, "application/octet-stream", bytes.NewReader(reqBytes))
+	resp, err := e.httpClient.Do(httpReq.WithContext(ctx))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if resp.Body != nil {
 		defer resp.Body.Close()
 	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, nil, newHTTPError(resp)
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, ErrInvalidRequest
+		return nil, nil, ErrInvalidRequest
 	}
 
-	respMsg, err := cryptopro.OpenToDecode(resp.Body)
-
-	cBuf := bytes.NewBuffer([]byte{})
-	_, err = io.Copy(cBuf, respMsg)
+	respBytes, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	return e.verify(respBytes)
+}
 
-	err = respMsg.Verify(e.crt)
-	if err != nil && err != cryptopro.ErrVerifyingSignature {
-		return nil, ErrInvalidCertificate
+// verify проверяет подпись ответа и отклоняет ответ без подтвержденной подписи, если прием
+// таких ответов не разрешен SetInsecureAcceptUnverified.
+func (e *equifaxCredit) verify(respBytes []byte) ([]byte, *SignatureInfo, error) {
+	var (
+		content   []byte
+		signature *SignatureInfo
+		err       error
+	)
+
+	if bytes.HasPrefix(bytes.TrimSpace(respBytes), []byte("<")) {
+		content, signature = respBytes, &SignatureInfo{Err: ErrResponseNotSigned}
+	} else if e.verifier == nil {
+		return nil, nil, ErrInvalidSignature
+	} else if content, signature, err = e.verifier.Verify(respBytes); err != nil {
+		return nil, nil, errors.Wrap(ErrInvalidSignature, err.Error())
 	}
 
-	var result *CreditResponse
-	dec := xml.NewDecoder(cBuf)
-	dec.CharsetReader = acharset.CharsetReader
-	err = dec.Decode(&result)
+	if !e.insecure && !signature.Verified {
+		return nil, nil, signature.Err
+	}
+	return content, signature, nil
+}
 
-	if err != nil {
+// signContext подписывает запрос с учетом ctx. ContextSigner получает ctx и сам прекращает
+// подпись. Подпись Signer прервать нельзя: при отмене ctx signContext возвращает ctx.Err(),
+// не дожидаясь ее окончания, а подпись продолжается в фоне, удерживая данные запроса и ресурсы
+// подписчика (например, контекст CSP), и ее результат отбрасывается.
+func (e *equifaxCredit) signContext(ctx context.Context, data []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
-	return result, nil
+	if signer, ok := e.signer.(ContextSigner); ok {
+		return signer.SignContext(ctx, data)
+	}
+
+	type signResult struct {
+		data []byte
+		err  error
+	}
+
+	done := make(chan signResult, 1)
+	go func() {
+		signed, err := e.signer.Sign(data)
+		done <- signResult{signed, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-done:
+		return res.data, res.err
+	}
 }
@@ -1,6 +1,7 @@
 package equifax
 
 import (
+	"context"
 	"encoding/xml"
 	"time"
 )
@@ -35,7 +36,7 @@ type NewApplication struct {
 	Sex                        Sex                    `xml:"sex" csv:"sex"`
 	Citizenship                Country                `xml:"citizenship" csv:"citizenship"`
 	INN                        EmptyString            `xml:"inn" csv:"inn"`
-	PFR                        EmptyString            `xml:"inn" csv:"pfr"`
+	PFR                        EmptyString            `xml:"pfr" csv:"pfr"`
 	DriverNo                   EmptyString            `xml:"driverno" csv:"driverno"`
 	Education                  Education              `xml:"education" csv:"education"`
 	Marital                    Marital                `xml:"marital" csv:"marital"`
@@ -132,9 +133,15 @@ type NewApplication struct {
 }
 
 type NewApplicationResponse struct {
-	XMLName       xml.Name `xml:"newApplicationResponse"`
-	ApplicationID string   `xml:"applicationid"`
-	Status        Status   `xml:"status"`
+	XMLName       xml.Name     `xml:"newApplicationResponse"`
+	ApplicationID string       `xml:"applicationid"`
+	Status        Status       `xml:"status"`
+	Warning       *StatusError `xml:"-"` // предупреждение (status 46, 47), запрос при этом выполнен
+}
+
+func (r *NewApplicationResponse) checkResponse() (err error) {
+	r.Warning, err = checkStatus(r.ApplicationID, r.Status)
+	return err
 }
 
 type OutputVector struct {
@@ -147,13 +154,19 @@ type OutputVector struct {
 }
 
 type OutputVectorResponse struct {
-	XMLName           xml.Name `xml:"outputVectorResponse"`
-	ApplicationID     string   `xml:"applicationid"`
-	Status            Status   `xml:"status"`
-	MainRules         string   `xml:"mainrules"`
-	MainScoreValue    int32    `xml:"mainscorevalue,-"`
-	SpecificRules     string   `xml:"specificrules"`
-	ApplicationsFound int32    `xml:"applicationsfound,-"`
+	XMLName           xml.Name     `xml:"outputVectorResponse"`
+	ApplicationID     string       `xml:"applicationid"`
+	Status            Status       `xml:"status"`
+	MainRules         string       `xml:"mainrules"`
+	MainScoreValue    int32        `xml:"mainscorevalue,-"`
+	SpecificRules     string       `xml:"specificrules"`
+	ApplicationsFound int32        `xml:"applicationsfound,-"`
+	Warning           *StatusError `xml:"-"` // предупреждение (status 46, 47), запрос при этом выполнен
+}
+
+func (r *OutputVectorResponse) checkResponse() (err error) {
+	r.Warning, err = checkStatus(r.ApplicationID, r.Status)
+	return err
 }
 
 type UpdateCreditStatus struct {
@@ -169,9 +182,15 @@ type UpdateCreditStatus struct {
 }
 
 type UpdateCreditStatusResponse struct {
-	XMLName       xml.Name `xml:"updateCreditStatusResponse"`
-	ApplicationID string   `xml:"applicationid"`
-	Status        Status   `xml:"status"`
+	XMLName       xml.Name     `xml:"updateCreditStatusResponse"`
+	ApplicationID string       `xml:"applicationid"`
+	Status        Status       `xml:"status"`
+	Warning       *StatusError `xml:"-"` // предупреждение (status 46, 47), запрос при этом выполнен
+}
+
+func (r *UpdateCreditStatusResponse) checkResponse() (err error) {
+	r.Warning, err = checkStatus(r.ApplicationID, r.Status)
+	return err
 }
 
 type UpdateFraudStatus struct {
@@ -184,9 +203,15 @@ type UpdateFraudStatus struct {
 }
 
 type UpdateFraudStatusResponse struct {
-	XMLName       xml.Name `updateFraudStatusResponse"`
-	ApplicationID string   `xml:"applicationid"`
-	Status        Status   `xml:"status"`
+	XMLName       xml.Name     `xml:"updateFraudStatusResponse"`
+	ApplicationID string       `xml:"applicationid"`
+	Status        Status       `xml:"status"`
+	Warning       *StatusError `xml:"-"` // предупреждение (status 46, 47), запрос при этом выполнен
+}
+
+func (r *UpdateFraudStatusResponse) checkResponse() (err error) {
+	r.Warning, err = checkStatus(r.ApplicationID, r.Status)
+	return err
 }
 
 type UpdateDefaultStatus struct {
@@ -202,9 +227,15 @@ type UpdateDefaultStatus struct {
 }
 
 type UpdateDefaultStatusResponse struct {
-	XMLName       xml.Name `xml:"updateDefaultStatusResponse"`
-	ApplicationID string   `xml:"applicationid"`
-	Status        Status   `xml:"status"`
+	XMLName       xml.Name     `xml:"updateDefaultStatusResponse"`
+	ApplicationID string       `xml:"applicationid"`
+	Status        Status       `xml:"status"`
+	Warning       *StatusError `xml:"-"` // предупреждение (status 46, 47), запрос при этом выполнен
+}
+
+func (r *UpdateDefaultStatusResponse) checkResponse() (err error) {
+	r.Warning, err = checkStatus(r.ApplicationID, r.Status)
+	return err
 }
 
 type ProcessingApplication struct {
@@ -218,9 +249,15 @@ type ProcessingApplication struct {
 }
 
 type ProcessingApplicationResponse struct {
-	XMLName       xml.Name `xml:"processingApplicationResponse"`
-	ApplicationID string   `xml:"applicationid"`
-	Status        Status   `xml:"status"`
+	XMLName       xml.Name     `xml:"processingApplicationResponse"`
+	ApplicationID string       `xml:"applicationid"`
+	Status        Status       `xml:"status"`
+	Warning       *StatusError `xml:"-"` // предупреждение (status 46, 47), запрос при этом выполнен
+}
+
+func (r *ProcessingApplicationResponse) checkResponse() (err error) {
+	r.Warning, err = checkStatus(r.ApplicationID, r.Status)
+	return err
 }
 
 type DeleteApplication struct {
@@ -233,9 +270,15 @@ type DeleteApplication struct {
 }
 
 type DeleteApplicationResponse struct {
-	XMLName       xml.Name `xml:"deleteApplicationResponse"`
-	ApplicationID string   `xml:"applicationid"`
-	Status        Status   `xml:"status"`
+	XMLName       xml.Name     `xml:"deleteApplicationResponse"`
+	ApplicationID string       `xml:"applicationid"`
+	Status        Status       `xml:"status"`
+	Warning       *StatusError `xml:"-"` // предупреждение (status 46, 47), запрос при этом выполнен
+}
+
+func (r *DeleteApplicationResponse) checkResponse() (err error) {
+	r.Warning, err = checkStatus(r.ApplicationID, r.Status)
+	return err
 }
 
 type EquifaxFraud interface {
@@ -248,6 +291,33 @@ type EquifaxFraud interface {
 	DeleteApplication(req *DeleteApplication) (*DeleteApplicationResponse, error)
 }
 
+// EquifaxFraudContext - вариант EquifaxFraud, в котором каждый вызов принимает context.Context
+// для отмены запроса и передачи дедлайна в HTTP-запрос.
+type EquifaxFraudContext interface {
+	NewApplicationContext(ctx context.Context, req *NewApplication) (*NewApplicationResponse, error)
+	OutputVectorContext(ctx context.Context, req *OutputVector) (*OutputVectorResponse, error)
+	UpdateCreditStatusContext(ctx context.Context, req *UpdateCreditStatus) (*UpdateCreditStatusResponse, error)
+	UpdateFraudStatusContext(ctx context.Context, req *UpdateFraudStatus) (*UpdateFraudStatusResponse, error)
+	UpdateDefaultStatusContext(ctx context.Context, req *UpdateDefaultStatus) (*UpdateDefaultStatusResponse, error)
+	ProcessingApplicationContext(ctx context.Context, req *ProcessingApplication) (*ProcessingApplicationResponse, error)
+	DeleteApplicationContext(ctx context.Context, req *DeleteApplication) (*DeleteApplicationResponse, error)
+}
+
+// EquifaxFraudConfig - настройка клиента FPS.
+type EquifaxFraudConfig interface {
+	SetRetryPolicy(policy RetryPolicy)
+}
+
+// FraudClient - клиент FPS, который возвращают конструкторы. EquifaxFraud остается прежним
+// набором вызовов, поэтому его сторонние реализации и моки не ломаются при добавлении
+// новых вызовов и настроек.
+type FraudClient interface {
+	EquifaxFraud
+	EquifaxFraudContext
+	EquifaxFraudConfig
+	UpdateDefaultStatus(req *UpdateDefaultStatus) (*UpdateDefaultStatusResponse, error)
+}
+
 type equifaxFraud struct {
 	client    *SOAPClient
 	login     string
@@ -255,11 +325,43 @@ type equifaxFraud struct {
 	partnerID string
 }
 
+// NewEquifaxFraud создает клиент FPS с проверкой сертификата сервиса по системным корневым
+// сертификатам; enabledTLS не используется, см. NewSOAPClient.
 func NewEquifaxFraud(
 	url string, login string, password string, partnerID string, enabledTLS bool,
 	timeout time.Duration, auth *BasicAuth, logger Logger,
-) EquifaxFraud {
+) FraudClient {
 	client := NewSOAPClient(url, enabledTLS, timeout, auth, logger)
+	return newEquifaxFraud(client, login, password, partnerID)
+}
+
+// NewEquifaxFraudTLS создает клиент FPS с настройками TLS tlsConfig: корневыми сертификатами
+// сервиса и клиентским сертификатом партнера.
+func NewEquifaxFraudTLS(
+	url string, login string, password string, partnerID string, tlsConfig *TLSConfig,
+	timeout time.Duration, auth *BasicAuth, logger Logger,
+) (FraudClient, error) {
+	client, err := NewSOAPClientTLS(url, tlsConfig, timeout, auth, logger)
+	if err != nil {
+		return nil, err
+	}
+	return newEquifaxFraud(client, login, password, partnerID), nil
+}
+
+// NewEquifaxFraudConfig создает клиент FPS с пулом соединений, прокси и таймаутами
+// по настройкам config.
+func NewEquifaxFraudConfig(
+	url string, login string, password string, partnerID string, config *TransportConfig,
+	auth *BasicAuth, logger Logger,
+) (FraudClient, error) {
+	client, err := NewSOAPClientConfig(url, config, auth, logger)
+	if err != nil {
+		return nil, err
+	}
+	return newEquifaxFraud(client, login, password, partnerID), nil
+}
+
+func newEquifaxFraud(client *SOAPClient, login string, password string, partnerID string) *equifaxFraud {
 	return &equifaxFraud{
 		client:    client,
 		login:     login,
@@ -272,15 +374,27 @@ func (s *equifaxFraud) SetHeader(header interface{}) {
 	s.client.SetHeader(header)
 }
 
-func (s *equifaxFraud) NewApplication(req *NewApplication) (*NewApplicationResponse, error) {
-	response := new(NewApplicationResponse)
-	req.Credential = Credential{
+func (s *equifaxFraud) SetRetryPolicy(policy RetryPolicy) {
+	s.client.SetRetryPolicy(policy)
+}
+
+func (s *equifaxFraud) credential() Credential {
+	return Credential{
 		Login:     s.login,
 		Password:  s.password,
 		PartnerID: s.partnerID,
 	}
+}
+
+func (s *equifaxFraud) NewApplication(req *NewApplication) (*NewApplicationResponse, error) {
+	return s.NewApplicationContext(context.Background(), req)
+}
+
+func (s *equifaxFraud) NewApplicationContext(ctx context.Context, req *NewApplication) (*NewApplicationResponse, error) {
+	response := new(NewApplicationResponse)
+	req.Credential = s.credential()
 
-	err := s.client.Call("#newApplication", req, response)
+	err := s.client.CallContext(ctx, "#newApplication", req, response)
 	if err != nil {
 		return nil, err
 	}
@@ -288,28 +402,29 @@ func (s *equifaxFraud) NewApplication(req *NewApplication) (*NewApplicationRespo
 }
 
 func (s *equifaxFraud) OutputVector(req *OutputVector) (*OutputVectorResponse, error) {
+	return s.OutputVectorContext(context.Background(), req)
+}
+
+func (s *equifaxFraud) OutputVectorContext(ctx context.Context, req *OutputVector) (*OutputVectorResponse, error) {
 	response := new(OutputVectorResponse)
-	req.Credential = Credential{
-		Login:     s.login,
-		Password:  s.password,
-		PartnerID: s.partnerID,
-	}
-	err := s.client.Call("#outputVector", req, response)
+	req.Credential = s.credential()
+
+	err := s.client.callContext(ctx, "#outputVector", req, response, true)
 	if err != nil {
 		return nil, err
 	}
-
 	return response, nil
 }
 
 func (s *equifaxFraud) UpdateCreditStatus(req *UpdateCreditStatus) (*UpdateCreditStatusResponse, error) {
+	return s.UpdateCreditStatusContext(context.Background(), req)
+}
+
+func (s *equifaxFraud) UpdateCreditStatusContext(ctx context.Context, req *UpdateCreditStatus) (*UpdateCreditStatusResponse, error) {
 	response := new(UpdateCreditStatusResponse)
-	req.Credential = Credential{
-		Login:     s.login,
-		Password:  s.password,
-		PartnerID: s.partnerID,
-	}
-	err := s.client.Call("#updateCreditStatus", req, response)
+	req.Credential = s.credential()
+
+	err := s.client.CallContext(ctx, "#updateCreditStatus", req, response)
 	if err != nil {
 		return nil, err
 	}
@@ -317,13 +432,14 @@ func (s *equifaxFraud) UpdateCreditStatus(req *UpdateCreditStatus) (*UpdateCredi
 }
 
 func (s *equifaxFraud) UpdateFraudStatus(req *UpdateFraudStatus) (*UpdateFraudStatusResponse, error) {
+	return s.UpdateFraudStatusContext(context.Background(), req)
+}
+
+func (s *equifaxFraud) UpdateFraudStatusContext(ctx context.Context, req *UpdateFraudStatus) (*UpdateFraudStatusResponse, error) {
 	response := new(UpdateFraudStatusResponse)
-	req.Credential = Credential{
-		Login:     s.login,
-		Password:  s.password,
-		PartnerID: s.partnerID,
-	}
-	err := s.client.Call("#updateFraudStatus", req, response)
+	req.Credential = s.credential()
+
+	err := s.client.CallContext(ctx, "#updateFraudStatus", req, response)
 	if err != nil {
 		return nil, err
 	}
@@ -331,13 +447,14 @@ func (s *equifaxFraud) UpdateFraudStatus(req *UpdateFraudStatus) (*UpdateFraudSt
 }
 
 func (s *equifaxFraud) UpdateDefaultStatus(req *UpdateDefaultStatus) (*UpdateDefaultStatusResponse, error) {
+	return s.UpdateDefaultStatusContext(context.Background(), req)
+}
+
+func (s *equifaxFraud) UpdateDefaultStatusContext(ctx context.Context, req *UpdateDefaultStatus) (*UpdateDefaultStatusResponse, error) {
 	response := new(UpdateDefaultStatusResponse)
-	req.Credential = Credential{
-		Login:     s.login,
-		Password:  s.password,
-		PartnerID: s.partnerID,
-	}
-	err := s.client.Call("#updateDefaultStatus", req, response)
+	req.Credential = s.credential()
+
+	err := s.client.CallContext(ctx, "#updateDefaultStatus", req, response)
 	if err != nil {
 		return nil, err
 	}
@@ -345,13 +462,14 @@ func (s *equifaxFraud) UpdateDefaultStatus(req *UpdateDefaultStatus) (*UpdateDef
 }
 
 func (s *equifaxFraud) ProcessingApplication(req *ProcessingApplication) (*ProcessingApplicationResponse, error) {
+	return s.ProcessingApplicationContext(context.Background(), req)
+}
+
+func (s *equifaxFraud) ProcessingApplicationContext(ctx context.Context, req *ProcessingApplication) (*ProcessingApplicationResponse, error) {
 	response := new(ProcessingApplicationResponse)
-	req.Credential = Credential{
-		Login:     s.login,
-		Password:  s.password,
-		PartnerID: s.partnerID,
-	}
-	err := s.client.Call("#processingApplication", req, response)
+	req.Credential = s.credential()
+
+	err := s.client.CallContext(ctx, "#processingApplication", req, response)
 	if err != nil {
 		return nil, err
 	}
@@ -359,13 +477,14 @@ func (s *equifaxFraud) ProcessingApplication(req *ProcessingApplication) (*Proce
 }
 
 func (s *equifaxFraud) DeleteApplication(req *DeleteApplication) (*DeleteApplicationResponse, error) {
+	return s.DeleteApplicationContext(context.Background(), req)
+}
+
+func (s *equifaxFraud) DeleteApplicationContext(ctx context.Context, req *DeleteApplication) (*DeleteApplicationResponse, error) {
 	response := new(DeleteApplicationResponse)
-	req.Credential = Credential{
-		Login:     s.login,
-		Password:  s.password,
-		PartnerID: s.partnerID,
-	}
-	err := s.client.Call("#deleteApplication", req, response)
+	req.Credential = s.credential()
+
+	err := s.client.CallContext(ctx, "#deleteApplication", req, response)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,315 @@
+package equifaxtest
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/l-vitaly/acharset"
+	"github.com/l-vitaly/equifax"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// CreditReply - заранее заданный ответ CreditServer на очередной запрос.
+type CreditReply struct {
+	Code       equifax.ResponseCode // responsecode ответа
+	Text       string               // responsestring ответа
+	Report     []byte               // готовый документ bki_response в UTF-8, заменяет Code и Text
+	Unsigned   bool                 // вернуть ответ без подписи
+	Delay      time.Duration        // задержка перед ответом
+	HTTPStatus int                  // HTTP-статус ответа; при статусе, отличном от 200, тело не передается
+}
+
+// CreditServer - поддельная точка bki_request кредитного бюро.
+//
+// Сервер проверяет подпись запроса, схему XSD и код партнера так же, как бюро, и отвечает
+// кодами 19, 11, 12, 15 и 5 соответственно. Корректные запросы получают ответы из очереди
+// Enqueue, а при пустой очереди - код 3 (заёмщик не найден). Ответы подписываются Signer.
+type CreditServer struct {
+	*httptest.Server
+	Signer *equifax.PKCS7Signer // подпись ответов бюро; сертификат передается в TrustStore клиента
+
+	partnerID string
+	schema    string
+
+	mu       sync.Mutex
+	verifier equifax.Verifier
+	replies  []CreditReply
+	requests []*equifax.CreditRequest
+}
+
+// NewCreditServer запускает сервер для партнера partnerID; если schema не пустая,
+// запросы проверяются по XSD-схеме из этого файла.
+func NewCreditServer(partnerID string, schema string) (*CreditServer, error) {
+	signer, err := NewSigner("equifax")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &CreditServer{
+		Signer:    signer,
+		partnerID: partnerID,
+		schema:    schema,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s, nil
+}
+
+// NewSigner создает подписанта с самоподписанным сертификатом ECDSA P-256 на имя cn.
+func NewSigner(cn string) (*equifax.PKCS7Signer, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, key.Public(), key)
+	if err != nil {
+		return nil, err
+	}
+
+	crt, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &equifax.PKCS7Signer{Certificate: crt, Key: key}, nil
+}
+
+// SetVerifier задает проверку подписи запросов; запросы без подтвержденной подписи получают код 11.
+// По умолчанию принимается любая верная подпись PKCS#7.
+func (s *CreditServer) SetVerifier(verifier equifax.Verifier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.verifier = verifier
+}
+
+// Enqueue задает ответы на следующие корректные запросы.
+func (s *CreditServer) Enqueue(replies ...CreditReply) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replies = append(s.replies, replies...)
+}
+
+// Requests возвращает принятые сервером запросы.
+func (s *CreditServer) Requests() []*equifax.CreditRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*equifax.CreditRequest(nil), s.requests...)
+}
+
+type bkiRequest struct {
+	XMLName   xml.Name                 `xml:"bki_request"`
+	Version   string                   `xml:"version,attr"`
+	PartnerID string                   `xml:"partnerid,attr"`
+	PackageID string                   `xml:"packageid,attr"`
+	RecCount  string                   `xml:"reccount,attr"`
+	Requests  []*equifax.CreditRequest `xml:"request"`
+}
+
+func (s *CreditServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	req, reply := s.handle(body)
+	if reply.Delay > 0 {
+		select {
+		case <-time.After(reply.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if reply.HTTPStatus != 0 && reply.HTTPStatus != http.StatusOK {
+		w.WriteHeader(reply.HTTPStatus)
+		return
+	}
+
+	report := reply.Report
+	if report == nil {
+		num := 0
+		if req != nil && len(req.Requests) > 0 {
+			num = req.Requests[0].Num
+		}
+		report = []byte(fmt.Sprintf(
+			`<?xml version="1.0" encoding="utf-8"?>`+"\n"+
+				`<bki_response version="%s" partnerid="%s" datetime="%s"><response num="%d"><responsecode>%d</responsecode><responsestring>%s</responsestring></response></bki_response>`,
+			equifax.EquifaxCreditVersion, escape(s.partnerID), time.Now().In(equifax.BureauLocation).Format("02.01.2006 15:04:05"),
+			num, reply.Code, escape(reply.Text),
+		))
+	}
+
+	report = bytes.Replace(report, []byte(`encoding="utf-8"`), []byte(`encoding="windows-1251"`), 1)
+	report, err = charmap.Windows1251.NewEncoder().Bytes(report)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if !reply.Unsigned {
+		if report, err = s.Signer.Sign(report); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(report)
+}
+
+// handle проверяет запрос и выбирает ответ на него.
+func (s *CreditServer) handle(body []byte) (*bkiRequest, CreditReply) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("<")) {
+		return nil, codeReply(equifax.ResponseCodeType19)
+	}
+
+	content, ok := s.verify(body)
+	if !ok {
+		return nil, codeReply(equifax.ResponseCodeType11)
+	}
+
+	req := new(bkiRequest)
+	dec := xml.NewDecoder(bytes.NewReader(content))
+	dec.CharsetReader = acharset.CharsetReader
+	if err := dec.Decode(req); err != nil {
+		return nil, codeReply(equifax.ResponseCodeType12)
+	}
+
+	// схема описывает bki_request с одним запросом, пакеты проверяются по reccount
+	if req.PackageID == "" && s.schema != "" {
+		if err := equifax.ValidateSchema(s.schema, content); err != nil {
+			return nil, codeReply(equifax.ResponseCodeType12)
+		}
+	}
+
+	switch {
+	case req.Version != equifax.EquifaxCreditVersion:
+		return req, codeReply(equifax.ResponseCodeType15)
+	case req.PartnerID != s.partnerID:
+		return req, codeReply(equifax.ResponseCodeType5)
+	case len(req.Requests) == 0:
+		return req, codeReply(equifax.ResponseCodeType12)
+	case req.PackageID == "" && len(req.Requests) > 1:
+		return req, codeReply(equifax.ResponseCodeType12)
+	case req.PackageID != "" && !validPackage(req):
+		return req, codeReply(equifax.ResponseCodeType12)
+	}
+
+	replies := make([]CreditReply, len(req.Requests))
+	for i, r := range req.Requests {
+		s.requests = append(s.requests, r)
+		replies[i] = codeReply(equifax.ResponseCodeType3)
+		if len(s.replies) > 0 {
+			replies[i] = s.replies[0]
+			s.replies = s.replies[1:]
+		}
+	}
+	if req.PackageID == "" {
+		return req, replies[0]
+	}
+	return req, s.packageReply(req, replies)
+}
+
+// validPackage проверяет идентификатор пакета (typePackageID) и количество запросов
+// (typePackageRecCount).
+func validPackage(req *bkiRequest) bool {
+	if len(req.PackageID) > 15 || !isDigits(req.PackageID) || len(req.RecCount) > 4 || !isDigits(req.RecCount) {
+		return false
+	}
+	return atoi(req.RecCount) == len(req.Requests)
+}
+
+// packageReply собирает ответ на пакет: response каждого запроса из его ответа в очереди.
+// Для ответа с Report берется содержимое его response. Задержка, HTTP-статус и подпись
+// берутся из ответа на первый запрос.
+func (s *CreditServer) packageReply(req *bkiRequest, replies []CreditReply) CreditReply {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, `<?xml version="1.0" encoding="utf-8"?>`+"\n"+`<bki_response version="%s" partnerid="%s" packageid="%s" datetime="%s">`,
+		equifax.EquifaxCreditVersion, escape(s.partnerID), escape(req.PackageID), time.Now().In(equifax.BureauLocation).Format("02.01.2006 15:04:05"))
+
+	for i, reply := range replies {
+		fmt.Fprintf(buf, `<response num="%d">`, req.Requests[i].Num)
+		if inner := responseInner(reply.Report); inner != nil {
+			buf.Write(inner)
+		} else {
+			fmt.Fprintf(buf, `<responsecode>%d</responsecode><responsestring>%s</responsestring>`, reply.Code, escape(reply.Text))
+		}
+		buf.WriteString(`</response>`)
+	}
+	buf.WriteString(`</bki_response>`)
+
+	reply := replies[0]
+	reply.Report = buf.Bytes()
+	return reply
+}
+
+// responseInner возвращает содержимое элемента response готового отчета.
+func responseInner(report []byte) []byte {
+	start := bytes.Index(report, []byte("<response"))
+	end := bytes.LastIndex(report, []byte("</response>"))
+	if start < 0 || end < start {
+		return nil
+	}
+	open := bytes.IndexByte(report[start:], '>')
+	if open < 0 || start+open+1 > end {
+		return nil
+	}
+	return report[start+open+1 : end]
+}
+
+// verify проверяет подпись запроса и возвращает подписанный документ.
+func (s *CreditServer) verify(body []byte) ([]byte, bool) {
+	if s.verifier == nil {
+		// без доверенных сертификатов верная подпись отличается от неверной только ErrUntrustedSigner
+		content, info, err := new(equifax.PKCS7Verifier).Verify(body)
+		return content, err == nil && info.Err == equifax.ErrUntrustedSigner
+	}
+
+	content, info, err := s.verifier.Verify(body)
+	return content, err == nil && info.Verified
+}
+
+var responseTexts = map[equifax.ResponseCode]string{
+	equifax.ResponseCodeType3:  "заёмщик с такими данными не найден",
+	equifax.ResponseCodeType5:  "нет такого Партнера",
+	equifax.ResponseCodeType11: "подпись запроса не соответствует Партнеру",
+	equifax.ResponseCodeType12: "структура XML запроса не корректна",
+	equifax.ResponseCodeType15: "неверная версия XML-запроса",
+	equifax.ResponseCodeType19: "запрос не подписан",
+}
+
+func codeReply(code equifax.ResponseCode) CreditReply {
+	return CreditReply{Code: code, Text: responseTexts[code]}
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,327 @@
+// Package equifaxtest содержит поддельные сервисы Equifax для тестов без доступа к бюро.
+package equifaxtest
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/l-vitaly/equifax"
+)
+
+// Reply - заранее заданный ответ FraudServer на очередной вызов операции.
+type Reply struct {
+	Status     equifax.Status     // status в теле ответа
+	Fault      *equifax.SOAPFault // вернуть SOAP Fault вместо ответа
+	Delay      time.Duration      // задержка перед ответом
+	Malformed  bool               // вернуть тело, которое не является SOAP-конвертом
+	HTTPStatus int                // HTTP-статус ответа, по умолчанию 200 (500 для Fault)
+}
+
+// Application - состояние заявки, переданной в FraudServer.
+type Application struct {
+	ID            string
+	Fields        map[string]string // поля newApplication
+	Status        equifax.ApplicationStatus
+	FraudStatus   equifax.ApplicationFraudStatus
+	DefaultStatus equifax.DefaultStatus
+	Processed     bool
+}
+
+// FraudServer - поддельный SOAP-сервис FPS Partner, хранящий заявки в памяти.
+//
+// Операции newApplication, outputVector, updateCreditStatus, updateFraudStatus,
+// updateDefaultStatus, processingApplication и deleteApplication обрабатываются так же,
+// как в FPS: статусы 3, 14, 15, 23-28 возвращаются по состоянию заявки. Очередь ответов,
+// заданная через Enqueue, имеет приоритет над этим поведением.
+type FraudServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	apps    map[string]*Application
+	replies map[string][]Reply
+	calls   map[string]int
+}
+
+func NewFraudServer() *FraudServer {
+	s := newFraudServer()
+	s.Start()
+	return s
+}
+
+// NewTLSFraudServer создает FraudServer, доступный по HTTPS с сертификатом httptest.
+// config дополняет настройки TLS сервера, например проверкой клиентских сертификатов
+// (ClientAuth, ClientCAs); может быть nil.
+func NewTLSFraudServer(config *tls.Config) *FraudServer {
+	s := newFraudServer()
+	if config != nil {
+		s.TLS = config
+	}
+	s.StartTLS()
+	return s
+}
+
+func newFraudServer() *FraudServer {
+	s := &FraudServer{
+		apps:    make(map[string]*Application),
+		replies: make(map[string][]Reply),
+		calls:   make(map[string]int),
+	}
+	s.Server = httptest.NewUnstartedServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Enqueue задает ответы на следующие вызовы операции action (например, "newApplication").
+func (s *FraudServer) Enqueue(action string, replies ...Reply) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replies[action] = append(s.replies[action], replies...)
+}
+
+// Application возвращает копию текущего состояния заявки.
+func (s *FraudServer) Application(id string) (Application, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	app, ok := s.apps[id]
+	if !ok {
+		return Application{}, false
+	}
+	return *app, true
+}
+
+// Calls возвращает количество вызовов операции action.
+func (s *FraudServer) Calls(action string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[action]
+}
+
+func (s *FraudServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	action, fields, err := parseSOAPRequest(body)
+	if err != nil {
+		writeFault(w, &equifax.SOAPFault{Code: "soapenv:Client", String: err.Error()}, 0)
+		return
+	}
+
+	reply, scripted := s.next(action)
+	if reply.Delay > 0 {
+		select {
+		case <-time.After(reply.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	switch {
+	case reply.Fault != nil:
+		writeFault(w, reply.Fault, reply.HTTPStatus)
+		return
+	case reply.Malformed:
+		w.WriteHeader(httpStatus(reply.HTTPStatus, http.StatusOK))
+		w.Write([]byte("<html><body>Service Temporarily Unavailable"))
+		return
+	}
+
+	var extra string
+	status := reply.Status
+	if !scripted {
+		var ok bool
+		if status, extra, ok = s.handle(action, fields); !ok {
+			writeFault(w, &equifax.SOAPFault{Code: "soapenv:Client", String: "unknown operation " + action}, 0)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.WriteHeader(httpStatus(reply.HTTPStatus, http.StatusOK))
+	fmt.Fprintf(w, envelope, fmt.Sprintf(
+		`<fps:%[1]sResponse xmlns:fps="http://example.org/FPSPartner"><applicationid>%[2]s</applicationid><status>%[3]d</status>%[4]s</fps:%[1]sResponse>`,
+		action, escape(fields["applicationid"]), status, extra,
+	))
+}
+
+func (s *FraudServer) next(action string) (Reply, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls[action]++
+
+	queue := s.replies[action]
+	if len(queue) == 0 {
+		return Reply{}, false
+	}
+	s.replies[action] = queue[1:]
+	return queue[0], true
+}
+
+// closedAutomatically - значение статуса «Закрыт автоматически», которое партнер не передает.
+const closedAutomatically = "8"
+
+// handle выполняет операцию над заявками и возвращает status и дополнительные поля ответа;
+// ok == false для неизвестной операции.
+func (s *FraudServer) handle(action string, fields map[string]string) (status equifax.Status, extra string, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := fields["applicationid"]
+
+	if action == "newApplication" {
+		if id == "" || fields["lastname"] == "" || fields["firstname"] == "" {
+			return equifax.StatusType2, "", true
+		}
+		if _, found := s.apps[id]; found {
+			return equifax.StatusType15, "", true
+		}
+		s.apps[id] = &Application{
+			ID:            id,
+			Fields:        fields,
+			Status:        equifax.ApplicationStatus(atoi(fields["applicationstatus"])),
+			FraudStatus:   equifax.ApplicationFraudStatus(atoi(fields["applicationfraudstatus"])),
+			DefaultStatus: equifax.DefaultStatus(atoi(fields["defaultstatus"])),
+		}
+		return equifax.StatusType0, "", true
+	}
+
+	switch action {
+	case "outputVector", "updateCreditStatus", "updateFraudStatus", "updateDefaultStatus",
+		"processingApplication", "deleteApplication":
+	default:
+		return 0, "", false
+	}
+
+	app, found := s.apps[id]
+	if !found {
+		return equifax.StatusType3, "", true
+	}
+
+	switch action {
+	case "outputVector":
+		return equifax.StatusType0, fmt.Sprintf(
+			"<mainrules></mainrules><mainscorevalue>0</mainscorevalue><specificrules></specificrules><applicationsfound>%d</applicationsfound>",
+			len(s.apps)-1,
+		), true
+	case "updateCreditStatus":
+		value := fields["applicationstatus"]
+		if value == closedAutomatically {
+			return equifax.StatusType26, "", true
+		}
+		if equifax.ApplicationStatus(atoi(value)) == app.Status {
+			return equifax.StatusType23, "", true
+		}
+		app.Status = equifax.ApplicationStatus(atoi(value))
+	case "updateFraudStatus":
+		value := fields["applicationfraudstatus"]
+		if value == closedAutomatically {
+			return equifax.StatusType27, "", true
+		}
+		if equifax.ApplicationFraudStatus(atoi(value)) == app.FraudStatus {
+			return equifax.StatusType24, "", true
+		}
+		app.FraudStatus = equifax.ApplicationFraudStatus(atoi(value))
+	case "updateDefaultStatus":
+		value := fields["defaultstatus"]
+		if value == closedAutomatically {
+			return equifax.StatusType28, "", true
+		}
+		if equifax.DefaultStatus(atoi(value)) == app.DefaultStatus {
+			return equifax.StatusType25, "", true
+		}
+		app.DefaultStatus = equifax.DefaultStatus(atoi(value))
+	case "processingApplication":
+		if app.Processed {
+			return equifax.StatusType14, "", true
+		}
+		app.Processed = true
+	case "deleteApplication":
+		delete(s.apps, id)
+	}
+
+	return equifax.StatusType0, "", true
+}
+
+// parseSOAPRequest возвращает имя операции из тела SOAP-конверта и значения ее полей.
+func parseSOAPRequest(body []byte) (string, map[string]string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(body))
+
+	var (
+		action string
+		field  string
+		inBody bool
+		fields = make(map[string]string)
+	)
+
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			if action == "" {
+				return "", nil, fmt.Errorf("soap body not found: %v", err)
+			}
+			return action, fields, nil
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Local == "Body":
+				inBody = true
+			case inBody && action == "":
+				action = t.Name.Local
+			case action != "":
+				field = t.Name.Local
+			}
+		case xml.CharData:
+			if field != "" {
+				fields[field] += string(t)
+			}
+		case xml.EndElement:
+			field = ""
+			if t.Name.Local == action {
+				return action, fields, nil
+			}
+		}
+	}
+}
+
+const envelope = `<?xml version="1.0" encoding="UTF-8"?>
+<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/"><soapenv:Body>%s</soapenv:Body></soapenv:Envelope>`
+
+func writeFault(w http.ResponseWriter, fault *equifax.SOAPFault, status int) {
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.WriteHeader(httpStatus(status, http.StatusInternalServerError))
+	fmt.Fprintf(w, envelope, fmt.Sprintf(
+		"<soapenv:Fault><faultcode>%s</faultcode><faultstring>%s</faultstring></soapenv:Fault>",
+		escape(fault.Code), escape(fault.String),
+	))
+}
+
+func httpStatus(status, def int) int {
+	if status == 0 {
+		return def
+	}
+	return status
+}
+
+func escape(s string) string {
+	buf := new(bytes.Buffer)
+	xml.EscapeText(buf, []byte(s))
+	return buf.String()
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
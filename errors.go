@@ -0,0 +1,236 @@
+package equifax
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// ErrEmptyResponse возвращается, когда сервис FPS ответил успешным HTTP-статусом без тела.
+var ErrEmptyResponse = errors.New("empty response")
+
+// HTTPError возвращается, когда сервис ответил HTTP-статусом вне 2xx без SOAP Fault в теле.
+type HTTPError struct {
+	StatusCode int
+	Status     string
+}
+
+func newHTTPError(res *http.Response) *HTTPError {
+	return &HTTPError{StatusCode: res.StatusCode, Status: res.Status}
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("equifax: unexpected http status %s", e.Status)
+}
+
+// StatusError - код ошибки status из ответа сервиса FPS.
+type StatusError struct {
+	ApplicationID string
+	Status        Status
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("equifax: application %s: status %d: %s", e.ApplicationID, e.Status, e.MessageEN())
+}
+
+// MessageRU возвращает описание статуса на русском языке.
+func (e *StatusError) MessageRU() string {
+	if m, ok := statusMessages[e.Status]; ok {
+		return m.ru
+	}
+	return "неизвестный статус"
+}
+
+// MessageEN возвращает описание статуса на английском языке.
+func (e *StatusError) MessageEN() string {
+	if m, ok := statusMessages[e.Status]; ok {
+		return m.en
+	}
+	return "unknown status"
+}
+
+// IsWarning сообщает, что запрос выполнен и статус носит характер предупреждения.
+func (e *StatusError) IsWarning() bool {
+	return e.Status == StatusType46 || e.Status == StatusType47
+}
+
+// IsQuarantined сообщает, что заявка отправлена в карантин.
+func (e *StatusError) IsQuarantined() bool {
+	return e.Status == StatusType1
+}
+
+// IsDuplicate сообщает, что заявка, статус или фотография уже были переданы ранее.
+func (e *StatusError) IsDuplicate() bool {
+	switch e.Status {
+	case StatusType14, StatusType15, StatusType23, StatusType24, StatusType25, StatusType45, StatusType49:
+		return true
+	}
+	return false
+}
+
+// IsPhotoError сообщает об ошибке загрузки или обработки фотографии.
+func (e *StatusError) IsPhotoError() bool {
+	return e.Status >= StatusType39 && e.Status <= StatusType50 && !e.IsWarning()
+}
+
+// IsRetryable сообщает, что сервис временно недоступен и запрос можно повторить.
+func (e *StatusError) IsRetryable() bool {
+	return e.Status == StatusType30 || e.Status == StatusType98
+}
+
+func asStatusError(err error) (*StatusError, bool) {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr, true
+	}
+	return nil, false
+}
+
+func IsQuarantined(err error) bool {
+	statusErr, ok := asStatusError(err)
+	return ok && statusErr.IsQuarantined()
+}
+
+func IsDuplicate(err error) bool {
+	statusErr, ok := asStatusError(err)
+	return ok && statusErr.IsDuplicate()
+}
+
+func IsPhotoError(err error) bool {
+	statusErr, ok := asStatusError(err)
+	return ok && statusErr.IsPhotoError()
+}
+
+// IsRetryable сообщает, что err - StatusError временной недоступности сервиса (status 30, 98).
+func IsRetryable(err error) bool {
+	statusErr, ok := asStatusError(err)
+	return ok && statusErr.IsRetryable()
+}
+
+// checkStatus разбирает status ответа: для успешного ответа возвращает nil, nil,
+// для предупреждения - warning, для остальных статусов - ошибку.
+func checkStatus(applicationID string, status Status) (warning *StatusError, err error) {
+	if status == StatusType0 {
+		return nil, nil
+	}
+
+	statusErr := &StatusError{ApplicationID: applicationID, Status: status}
+	if statusErr.IsWarning() {
+		return statusErr, nil
+	}
+	return nil, statusErr
+}
+
+type statusMessage struct {
+	ru string
+	en string
+}
+
+var statusMessages = map[Status]statusMessage{
+	StatusType0:  {"без ошибок", "no errors"},
+	StatusType1:  {"заявка отправлена в карантин", "application is quarantined"},
+	StatusType2:  {"не заданы обязательные поля", "mandatory fields are missing"},
+	StatusType3:  {"не найдена заявка с переданным Application ID", "application with the given ID not found"},
+	StatusType4:  {"зарезервирован", "reserved"},
+	StatusType5:  {"данные не соответствуют формату", "data does not match the format"},
+	StatusType6:  {"ошибка сервиса нормализации", "normalization service error"},
+	StatusType7:  {"не задан статус кредитной заявки", "application status is not set"},
+	StatusType8:  {"не задан фрод-статус кредитной заявки", "application fraud status is not set"},
+	StatusType9:  {"не задан дефолт-статус кредитной заявки", "application default status is not set"},
+	StatusType10: {"выходной вектор еще не рассчитан", "output vector is not calculated yet"},
+	StatusType11: {"флаг предоставления выходного вектора из Системы не передавался. Выходной вектор не рассчитывался", "output vector flag was not passed, output vector was not calculated"},
+	StatusType12: {"кредитная заявка с данным ID обрабатывается другим пользователем", "application is being processed by another user"},
+	StatusType13: {"кредитная заявка для данного пользователя не доступна", "application is not available for this user"},
+	StatusType14: {"кредитная заявка уже была отправлена на обработку ранее", "application has already been sent for processing"},
+	StatusType15: {"кредитная заявка с данным ID уже есть в Системе", "application with the given ID already exists"},
+	StatusType20: {"зарезервирован", "reserved"},
+	StatusType21: {"зарезервирован", "reserved"},
+	StatusType22: {"зарезервирован", "reserved"},
+	StatusType23: {"невозможно установить данный статус кредитной заявки. Данный статус заявки уже был установлен ранее", "application status has already been set"},
+	StatusType24: {"невозможно установить данный фрод-статус кредитной заявки. Данный фрод-статус заявки уже был установлен ранее", "application fraud status has already been set"},
+	StatusType25: {"невозможно установить данный дефолт-статус кредитной заявки. Данный дефолт-статус заявки уже был установлен ранее", "application default status has already been set"},
+	StatusType26: {"неверно задан статус заявки. Статус «Закрыт автоматически» устанавливается в системе автоматически", "invalid application status: \"closed automatically\" is internal only"},
+	StatusType27: {"неверно задан фрод-статус заявки. Фрод-статус «Закрыт автоматически» устанавливается в системе автоматически", "invalid fraud status: \"closed automatically\" is internal only"},
+	StatusType28: {"неверно задан дефолт-статус заявки. Дефолт-статус «Закрыт автоматически» устанавливается в системе автоматически", "invalid default status: \"closed automatically\" is internal only"},
+	StatusType30: {"ошибка при обращении к ПО Оператора", "operator software call failed"},
+	StatusType31: {"не задан признак запроса к базе данных Участников", "participants database request flag is not set"},
+	StatusType39: {"контрольная сумма фотографии не совпадает с контрольной суммой, вычисленной Оператором", "photo checksum mismatch"},
+	StatusType40: {"при загрузке фотографии произошла ошибка. Файл фотографии не читается", "photo file is not readable"},
+	StatusType41: {"фотография не пригодна к обработке. На фотографии отсутствуют глаза", "photo is unusable: no eyes found"},
+	StatusType42: {"фотография не пригодна к обработке. Слишком низкое качество фотографии", "photo is unusable: quality is too low"},
+	StatusType43: {"фотография не пригодна к обработке. Лицо на фотографии обрезано", "photo is unusable: face is cropped"},
+	StatusType44: {"файл с фотографией не загружен. Размер файла фотографии превышает допустимый предел", "photo file is too large"},
+	StatusType45: {"для данной кредитной заявки уже имеется фотография Аппликанта", "application already has an applicant photo"},
+	StatusType46: {"на фотографии присутствует несколько лиц, выбрано наиболее крупное. Загрузка фотографии прошла успешно", "multiple faces on the photo, the largest one was selected; photo uploaded"},
+	StatusType47: {"фотография Аппликанта не найдена. При расчете выходного вектора не были использованы биометрические Правила", "applicant photo not found; biometric rules were not used"},
+	StatusType48: {"фотография Аппликанта не найдена для данной кредитной заявки. Выходной вектор не рассчитан", "applicant photo not found; output vector was not calculated"},
+	StatusType49: {"фотография с данным уникальным идентификатором уже имеется в Системе", "photo with the given ID already exists"},
+	StatusType50: {"фотография с данным уникальным идентификатором не найдена в Системе", "photo with the given ID not found"},
+	StatusType60: {"партнер заблокирован в системе", "partner is blocked"},
+	StatusType61: {"неправильное имя файла", "invalid file name"},
+	StatusType62: {"подпись и/или шифрование выполнены не корректно", "invalid signature or encryption"},
+	StatusType63: {"файл подписан неизвестным сертификатом", "file is signed with an unknown certificate"},
+	StatusType64: {"архив не распаковывается или является пустым", "archive cannot be unpacked or is empty"},
+	StatusType65: {"в архиве содержатся посторонние файлы", "archive contains unexpected files"},
+	StatusType66: {"передано некорректное количество полей", "invalid number of fields"},
+	StatusType67: {"архив с файлами фотографий не найден", "photo archive not found"},
+	StatusType68: {"файл с фотографией не найден в архиве", "photo file not found in the archive"},
+	StatusType90: {"ошибка справочника Oracle", "Oracle dictionary error"},
+	StatusType98: {"нет соединения с ЛБД", "no connection to the database"},
+	StatusType99: {"другая ошибка", "other error"},
+}
+
+// ResponseCodeError - код ошибки responsecode из ответа кредитного бюро.
+// Для известных кодов errors.Is сопоставляет ошибку с соответствующей ErrXXX.
+type ResponseCodeError struct {
+	Code ResponseCode
+	Text string
+}
+
+func (e *ResponseCodeError) Error() string {
+	return fmt.Sprintf("equifax: response code %d: %s", e.Code, e.Text)
+}
+
+func (e *ResponseCodeError) Unwrap() error {
+	if err, ok := responseCodeErrors[e.Code]; ok {
+		return err
+	}
+	return ErrUnknownResponseCode
+}
+
+func (e *ResponseCodeError) Cause() error {
+	return e.Unwrap()
+}
+
+var responseCodeErrors = map[ResponseCode]error{
+	ResponseCodeType4:  ErrReportTypeNotFound,
+	ResponseCodeType5:  ErrPartnerNotFound,
+	ResponseCodeType11: ErrSignatureMismatch,
+	ResponseCodeType12: ErrInvalidRequestXML,
+	ResponseCodeType15: ErrInvalidVersion,
+	ResponseCodeType19: ErrRequestNotSigned,
+	ResponseCodeType24: ErrNoHistoryOnDate,
+	ResponseCodeType30: ErrConsentMissing,
+	ResponseCodeType31: ErrInvalidConsentDate,
+	ResponseCodeType32: ErrApplicationMissing,
+	ResponseCodeType33: ErrInvalidReason,
+	ResponseCodeType34: ErrLegalEntityIDMissing,
+	ResponseCodeType36: ErrPfrNoMissing,
+	ResponseCodeType37: ErrReasonTextMissing,
+	ResponseCodeType99: ErrServiceUnavailable,
+}
+
+// checkResponseCode возвращает ошибку для всех кодов, кроме 0 (без ошибок), 1 (заёмщик найден)
+// и 3 (заёмщик не найден) - последний является штатным результатом запроса.
+func checkResponseCode(r *Response) error {
+	if r == nil {
+		return nil
+	}
+
+	switch r.Code {
+	case ResponseCodeType0, ResponseCodeType1, ResponseCodeType3:
+		return nil
+	}
+	return &ResponseCodeError{Code: r.Code, Text: r.Text}
+}
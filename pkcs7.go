@@ -0,0 +1,481 @@
+package equifax
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Подпись CMS (PKCS#7 SignedData) средствами стандартной библиотеки: RSA или ECDSA
+// с SHA-256. Используется в тестах и везде, где нет КриптоПро CSP.
+
+var (
+	ErrPKCS7Unsupported = errors.New("pkcs7: unsupported message")
+	ErrPKCS7Encoding    = errors.New("pkcs7: malformed encoding")
+	ErrPKCS7NoSigner    = errors.New("pkcs7: signer certificate not found")
+	ErrPKCS7Digest      = errors.New("pkcs7: message digest mismatch")
+)
+
+var (
+	oidData            = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidSignedData      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidContentType     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidSigningTime     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+	oidSHA256          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidRSAWithSHA256   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}
+	oidECDSAWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+)
+
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,optional,tag:0"`
+}
+
+type pkcs7EncapsulatedContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     []byte `asn1:"explicit,optional,tag:0"`
+}
+
+type pkcs7SignedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	ContentInfo      pkcs7EncapsulatedContentInfo
+	Certificates     asn1.RawValue     `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue     `asn1:"optional,tag:1"`
+	SignerInfos      []pkcs7SignerInfo `asn1:"set"`
+}
+
+// pkcs7SignedDataInfo - SignedData без разбора вложенных данных: в BER они бывают составной
+// OCTET STRING, а для сертификата подписанта и времени подписи не нужны.
+type pkcs7SignedDataInfo struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	ContentInfo      asn1.RawValue
+	Certificates     asn1.RawValue     `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue     `asn1:"optional,tag:1"`
+	SignerInfos      []pkcs7SignerInfo `asn1:"set"`
+}
+
+type pkcs7IssuerAndSerial struct {
+	Issuer asn1.RawValue
+	Serial *big.Int
+}
+
+type pkcs7SignerInfo struct {
+	Version            int
+	IssuerAndSerial    pkcs7IssuerAndSerial
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+	UnsignedAttrs      asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type pkcs7Attribute struct {
+	Type  asn1.ObjectIdentifier
+	Value asn1.RawValue // SET OF AttributeValue
+}
+
+// PKCS7Signer подписывает данные ключом Key; Certificate включается в сообщение.
+type PKCS7Signer struct {
+	Certificate *x509.Certificate
+	Key         crypto.Signer
+}
+
+func (s *PKCS7Signer) Sign(data []byte) ([]byte, error) {
+	return s.SignContext(context.Background(), data)
+}
+
+// SignContext проверяет ctx перед обращением к ключу Key, который может быть внешним
+// (HSM, токен), и не начинает подпись для отмененного запроса.
+func (s *PKCS7Signer) SignContext(ctx context.Context, data []byte) ([]byte, error) {
+	sigAlg, err := pkcs7SignatureAlgorithm(s.Key.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256(data)
+	attrs, err := pkcs7SignedAttributes(digest[:], time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	attrsDigest := sha256.Sum256(attrs)
+	signature, err := s.Key.Sign(rand.Reader, attrsDigest[:], crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+
+	// в SignerInfo атрибуты кодируются с неявным тегом [0] вместо SET
+	signedAttrs := append([]byte{0xa0}, attrs[1:]...)
+
+	sd := pkcs7SignedData{
+		Version:          1,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{{Algorithm: oidSHA256}},
+		ContentInfo: pkcs7EncapsulatedContentInfo{
+			ContentType: oidData,
+			Content:     data,
+		},
+		Certificates: asn1.RawValue{
+			Class:      asn1.ClassContextSpecific,
+			Tag:        0,
+			IsCompound: true,
+			Bytes:      s.Certificate.Raw,
+		},
+		SignerInfos: []pkcs7SignerInfo{{
+			Version: 1,
+			IssuerAndSerial: pkcs7IssuerAndSerial{
+				Issuer: asn1.RawValue{FullBytes: s.Certificate.RawIssuer},
+				Serial: s.Certificate.SerialNumber,
+			},
+			DigestAlgorithm:    pkix.AlgorithmIdentifier{Algorithm: oidSHA256},
+			SignedAttrs:        asn1.RawValue{FullBytes: signedAttrs},
+			SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: sigAlg},
+			Signature:          signature,
+		}},
+	}
+
+	content, err := asn1.Marshal(sd)
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(pkcs7ContentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{FullBytes: pkcs7Explicit(content)},
+	})
+}
+
+// PKCS7Verifier проверяет подпись сообщения сертификатом подписанта, вложенным в сообщение.
+// Подпись считается подтвержденной, только если этот сертификат есть в Trust.
+type PKCS7Verifier struct {
+	Trust *TrustStore
+}
+
+func (v *PKCS7Verifier) Verify(signed []byte) ([]byte, *SignatureInfo, error) {
+	sd, err := parsePKCS7(signed)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content := sd.ContentInfo.Content
+	info := new(SignatureInfo)
+
+	cert, signingTime, err := verifyPKCS7(sd, content)
+	if cert != nil {
+		info.Certificate = cert
+		info.Subject = cert.Subject.String()
+		info.SigningTime = signingTime
+	}
+
+	switch {
+	case err != nil:
+		info.Err = errors.Wrap(ErrInvalidSignature, err.Error())
+	case !v.Trust.Contains(cert):
+		info.Err = ErrUntrustedSigner
+	default:
+		info.Verified = true
+	}
+
+	return content, info, nil
+}
+
+func parsePKCS7(signed []byte) (*pkcs7SignedData, error) {
+	var ci pkcs7ContentInfo
+	if _, err := asn1.Unmarshal(signed, &ci); err != nil {
+		return nil, errors.Wrap(err, "pkcs7")
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return nil, ErrPKCS7Unsupported
+	}
+
+	sd := new(pkcs7SignedData)
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, sd); err != nil {
+		return nil, errors.Wrap(err, "pkcs7")
+	}
+	if len(sd.SignerInfos) == 0 {
+		return nil, ErrPKCS7NoSigner
+	}
+	return sd, nil
+}
+
+// verifyPKCS7 проверяет подпись первого подписанта и возвращает его сертификат и время подписи.
+// Сертификат возвращается и тогда, когда подпись неверна.
+func verifyPKCS7(sd *pkcs7SignedData, content []byte) (cert *x509.Certificate, signingTime time.Time, err error) {
+	si := sd.SignerInfos[0]
+
+	cert, err = pkcs7SignerCertificate(sd.Certificates, si)
+	if err != nil {
+		return nil, signingTime, err
+	}
+
+	if !si.DigestAlgorithm.Algorithm.Equal(oidSHA256) {
+		return cert, signingTime, ErrPKCS7Unsupported
+	}
+
+	sigAlg := x509.SHA256WithRSA
+	if _, ok := cert.PublicKey.(*ecdsa.PublicKey); ok {
+		sigAlg = x509.ECDSAWithSHA256
+	}
+
+	signedData := content
+	if len(si.SignedAttrs.FullBytes) > 0 {
+		digest := sha256.Sum256(content)
+		attrDigest, err := pkcs7Attr(si.SignedAttrs.Bytes, oidMessageDigest)
+		if err != nil {
+			return cert, signingTime, err
+		}
+
+		var messageDigest []byte
+		if _, err := asn1.Unmarshal(attrDigest, &messageDigest); err != nil {
+			return cert, signingTime, errors.Wrap(err, "pkcs7")
+		}
+		if !bytes.Equal(messageDigest, digest[:]) {
+			return cert, signingTime, ErrPKCS7Digest
+		}
+
+		signingTime = pkcs7SigningTime(si)
+
+		// подпись вычисляется от атрибутов, закодированных как SET
+		signedData = append([]byte{0x31}, si.SignedAttrs.FullBytes[1:]...)
+	}
+
+	if err := cert.CheckSignature(sigAlg, signedData, si.Signature); err != nil {
+		return cert, signingTime, errors.Wrap(err, "pkcs7")
+	}
+	return cert, signingTime, nil
+}
+
+// pkcs7SignerCertificate находит сертификат подписанта si среди вложенных в сообщение сертификатов.
+func pkcs7SignerCertificate(certificates asn1.RawValue, si pkcs7SignerInfo) (*x509.Certificate, error) {
+	certs, err := x509.ParseCertificates(certificates.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "pkcs7")
+	}
+
+	for _, c := range certs {
+		if bytes.Equal(c.RawIssuer, si.IssuerAndSerial.Issuer.FullBytes) && c.SerialNumber.Cmp(si.IssuerAndSerial.Serial) == 0 {
+			return c, nil
+		}
+	}
+	return nil, ErrPKCS7NoSigner
+}
+
+// pkcs7SigningTime возвращает время подписи из подписанных атрибутов si или нулевое время.
+func pkcs7SigningTime(si pkcs7SignerInfo) (signingTime time.Time) {
+	if len(si.SignedAttrs.FullBytes) == 0 {
+		return signingTime
+	}
+	if attrTime, err := pkcs7Attr(si.SignedAttrs.Bytes, oidSigningTime); err == nil {
+		asn1.Unmarshal(attrTime, &signingTime)
+	}
+	return signingTime
+}
+
+// pkcs7SignerDetails возвращает сертификат первого подписанта и время подписи сообщения без
+// проверки подписи - для верификаторов, которые проверяют подпись сами (CryptoProVerifier).
+// Если сообщение не удается разобрать или сертификат в него не вложен, возвращается nil.
+func pkcs7SignerDetails(signed []byte) (*x509.Certificate, time.Time) {
+	der, err := berToDER(signed)
+	if err != nil {
+		return nil, time.Time{}
+	}
+
+	var ci pkcs7ContentInfo
+	if _, err := asn1.Unmarshal(der, &ci); err != nil || !ci.ContentType.Equal(oidSignedData) {
+		return nil, time.Time{}
+	}
+
+	var sd pkcs7SignedDataInfo
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil || len(sd.SignerInfos) == 0 {
+		return nil, time.Time{}
+	}
+
+	si := sd.SignerInfos[0]
+	cert, _ := pkcs7SignerCertificate(sd.Certificates, si)
+	return cert, pkcs7SigningTime(si)
+}
+
+// berToDER заменяет неопределенные длины BER, которые использует потоковое кодирование
+// КриптоПро CSP, на определенные, чтобы сообщение можно было разобрать encoding/asn1.
+func berToDER(ber []byte) ([]byte, error) {
+	der, rest, err := berElement(ber)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, ErrPKCS7Encoding
+	}
+	return der, nil
+}
+
+// berElement перекодирует первый элемент ber и возвращает остаток.
+func berElement(ber []byte) (der []byte, rest []byte, err error) {
+	if len(ber) < 2 {
+		return nil, nil, ErrPKCS7Encoding
+	}
+
+	i := 1
+	if ber[0]&0x1f == 0x1f { // тег в нескольких октетах
+		for i < len(ber) && ber[i]&0x80 != 0 {
+			i++
+		}
+		i++
+	}
+	if i >= len(ber) {
+		return nil, nil, ErrPKCS7Encoding
+	}
+	tag, constructed := ber[:i], ber[0]&0x20 != 0
+
+	var content []byte
+	switch length := ber[i]; {
+	case length == 0x80:
+		if !constructed {
+			return nil, nil, ErrPKCS7Encoding
+		}
+		rest = ber[i+1:]
+		for {
+			if len(rest) < 2 {
+				return nil, nil, ErrPKCS7Encoding
+			}
+			if rest[0] == 0 && rest[1] == 0 {
+				rest = rest[2:]
+				break
+			}
+
+			var child []byte
+			if child, rest, err = berElement(rest); err != nil {
+				return nil, nil, err
+			}
+			content = append(content, child...)
+		}
+	default:
+		i++
+		n := int(length)
+		if length&0x80 != 0 {
+			size := int(length & 0x7f)
+			if size > 4 || i+size > len(ber) {
+				return nil, nil, ErrPKCS7Encoding
+			}
+			n = 0
+			for _, b := range ber[i : i+size] {
+				n = n<<8 | int(b)
+			}
+			i += size
+		}
+		if n < 0 || n > len(ber)-i {
+			return nil, nil, ErrPKCS7Encoding
+		}
+		content, rest = ber[i:i+n], ber[i+n:]
+
+		if constructed {
+			var elements []byte
+			for children := content; len(children) > 0; {
+				var child []byte
+				if child, children, err = berElement(children); err != nil {
+					return nil, nil, err
+				}
+				elements = append(elements, child...)
+			}
+			content = elements
+		}
+	}
+
+	der = append(append([]byte(nil), tag...), derLength(len(content))...)
+	return append(der, content...), rest, nil
+}
+
+func derLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+
+	var length []byte
+	for ; n > 0; n >>= 8 {
+		length = append([]byte{byte(n)}, length...)
+	}
+	return append([]byte{0x80 | byte(len(length))}, length...)
+}
+
+func pkcs7SignatureAlgorithm(pub crypto.PublicKey) (asn1.ObjectIdentifier, error) {
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		return oidRSAWithSHA256, nil
+	case *ecdsa.PublicKey:
+		return oidECDSAWithSHA256, nil
+	}
+	return nil, ErrPKCS7Unsupported
+}
+
+// pkcs7SignedAttributes кодирует атрибуты contentType, messageDigest и signingTime как SET OF Attribute.
+func pkcs7SignedAttributes(digest []byte, signingTime time.Time) ([]byte, error) {
+	values := []struct {
+		oid   asn1.ObjectIdentifier
+		value interface{}
+	}{
+		{oidContentType, oidData},
+		{oidMessageDigest, digest},
+		{oidSigningTime, signingTime.UTC()},
+	}
+
+	attrs := make([]pkcs7Attribute, 0, len(values))
+	for _, v := range values {
+		value, err := asn1.Marshal(v.value)
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, pkcs7Attribute{
+			Type: v.oid,
+			Value: asn1.RawValue{
+				Class:      asn1.ClassUniversal,
+				Tag:        asn1.TagSet,
+				IsCompound: true,
+				Bytes:      value,
+			},
+		})
+	}
+
+	return asn1.MarshalWithParams(attrs, "set")
+}
+
+// pkcs7Attr возвращает DER первого значения атрибута oid из содержимого SET OF Attribute.
+func pkcs7Attr(attrs []byte, oid asn1.ObjectIdentifier) ([]byte, error) {
+	for len(attrs) > 0 {
+		var attr pkcs7Attribute
+		rest, err := asn1.Unmarshal(attrs, &attr)
+		if err != nil {
+			return nil, errors.Wrap(err, "pkcs7")
+		}
+		if attr.Type.Equal(oid) {
+			return attr.Value.Bytes, nil
+		}
+		attrs = rest
+	}
+	return nil, errors.Errorf("pkcs7: attribute %s not found", oid)
+}
+
+func pkcs7Explicit(content []byte) []byte {
+	raw, _ := asn1.Marshal(asn1.RawValue{
+		Class:      asn1.ClassContextSpecific,
+		Tag:        0,
+		IsCompound: true,
+		Bytes:      content,
+	})
+	return raw
+}
@@ -0,0 +1,150 @@
+package equifax
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RetryPolicy решает, нужно ли повторить неудачную попытку обращения к Equifax.
+type RetryPolicy interface {
+	// Retry возвращает задержку перед следующей попыткой и признак необходимости повтора.
+	// attempt - номер завершившейся попытки начиная с 1, idempotent - можно ли повторно
+	// отправлять запрос, который сервер, возможно, уже принял.
+	Retry(attempt int, err error, idempotent bool) (time.Duration, bool)
+}
+
+type noRetry struct{}
+
+func (noRetry) Retry(int, error, bool) (time.Duration, bool) {
+	return 0, false
+}
+
+// NoRetry - политика по умолчанию: каждая ошибка сразу возвращается вызывающему.
+var NoRetry RetryPolicy = noRetry{}
+
+// Backoff - экспоненциальная задержка между попытками со случайным разбросом.
+type Backoff struct {
+	MaxAttempts int           // максимальное количество попыток, включая первую
+	Initial     time.Duration // задержка перед второй попыткой
+	Max         time.Duration // верхняя граница задержки
+	Multiplier  float64       // множитель задержки для каждой следующей попытки
+	Jitter      float64       // доля задержки (0..1), на которую она случайно отклоняется
+
+	// Retryable определяет повторяемые ошибки, по умолчанию ShouldRetry.
+	Retryable func(err error, idempotent bool) bool
+}
+
+func NewBackoff(maxAttempts int) *Backoff {
+	return &Backoff{
+		MaxAttempts: maxAttempts,
+		Initial:     200 * time.Millisecond,
+		Max:         5 * time.Second,
+		Multiplier:  2,
+		Jitter:      0.2,
+	}
+}
+
+func (b *Backoff) Retry(attempt int, err error, idempotent bool) (time.Duration, bool) {
+	if attempt >= b.MaxAttempts {
+		return 0, false
+	}
+
+	retryable := b.Retryable
+	if retryable == nil {
+		retryable = ShouldRetry
+	}
+	if !retryable(err, idempotent) {
+		return 0, false
+	}
+
+	multiplier := b.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(b.Initial) * math.Pow(multiplier, float64(attempt-1))
+	if b.Max > 0 && delay > float64(b.Max) {
+		delay = float64(b.Max)
+	}
+	if b.Jitter > 0 {
+		delay += delay * b.Jitter * (2*rand.Float64() - 1)
+	}
+	if delay < 0 {
+		delay = 0
+	}
+
+	return time.Duration(delay), true
+}
+
+// ShouldRetry сообщает, можно ли повторить запрос, завершившийся ошибкой err. В отличие от
+// IsRetryable учитывает не только status FPS, но и сетевые ошибки, коды кредитного бюро и
+// идемпотентность запроса.
+//
+// Ошибки установки соединения, а также явные отказы сервиса (status 30 и 98 FPS,
+// responsecode 99 кредитного бюро) повторяются всегда: запрос не был принят.
+// HTTP 5xx повторяется только для идемпотентных запросов, так как сервер мог успеть
+// обработать запрос (например, повторный newApplication вернет status 15).
+func ShouldRetry(err error, idempotent bool) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	if statusErr, ok := asStatusError(err); ok {
+		return statusErr.IsRetryable()
+	}
+
+	var codeErr *ResponseCodeError
+	if errors.As(err, &codeErr) {
+		return codeErr.Code == ResponseCodeType99
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return idempotent && httpErr.StatusCode >= 500
+	}
+
+	return false
+}
+
+// retry выполняет fn, пока policy разрешает повтор, с ожиданием между попытками.
+func retry(ctx context.Context, policy RetryPolicy, idempotent bool, logger Logger, fn func() error) error {
+	if policy == nil {
+		policy = NoRetry
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		delay, ok := policy.Retry(attempt, err, idempotent)
+		if !ok {
+			return err
+		}
+
+		logger.Log("equfax_retry", attempt, "delay", delay.String(), "error", err.Error())
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
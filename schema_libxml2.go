@@ -0,0 +1,73 @@
+//go:build !nolibxml2
+// +build !nolibxml2
+
+package equifax
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/lestrrat/go-libxml2/parser"
+	"github.com/lestrrat/go-libxml2/xsd"
+	"github.com/pkg/errors"
+)
+
+// schemas - разобранные XSD-схемы по пути к файлу. Схема читается и разбирается один раз
+// и больше не освобождается; проверка документа по разобранной схеме потокобезопасна.
+var schemas = struct {
+	sync.Mutex
+	m map[string]*xsd.Schema
+}{m: make(map[string]*xsd.Schema)}
+
+func loadSchema(schema string) (*xsd.Schema, error) {
+	schemas.Lock()
+	defer schemas.Unlock()
+
+	if sc, ok := schemas.m[schema]; ok {
+		return sc, nil
+	}
+
+	xsdSchema, err := ioutil.ReadFile(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	sc, err := xsd.Parse(xsdSchema)
+	if err != nil {
+		return nil, err
+	}
+	schemas.m[schema] = sc
+	return sc, nil
+}
+
+// ValidateSchema проверяет XML-документ data по XSD-схеме из файла schema. Схема разбирается
+// при первой проверке и кэшируется; ValidateSchema можно вызывать из нескольких горутин.
+// Не требующая libxml2 проверка запроса - ValidateCreditRequest.
+func ValidateSchema(schema string, data []byte) error {
+	sc, err := loadSchema(schema)
+	if err != nil {
+		return err
+	}
+
+	p := parser.New()
+	doc, err := p.ParseReader(bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	defer doc.Free()
+
+	if err := sc.Validate(doc); err != nil {
+		verr, ok := err.(xsd.SchemaValidationError)
+		if !ok {
+			return err
+		}
+		var msgs []string
+		for _, e := range verr.Errors() {
+			msgs = append(msgs, e.Error())
+		}
+		return errors.New(strings.Join(msgs, "\n"))
+	}
+	return nil
+}
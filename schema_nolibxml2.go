@@ -0,0 +1,10 @@
+//go:build nolibxml2
+// +build nolibxml2
+
+package equifax
+
+// ValidateSchema в сборке без libxml2 не проверяет документ и возвращает ErrSchemaUnsupported.
+// Запросы кредитного бюро проверяются без libxml2 функцией ValidateCreditRequest (SetValidation).
+func ValidateSchema(schema string, data []byte) error {
+	return ErrSchemaUnsupported
+}
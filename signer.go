@@ -0,0 +1,94 @@
+package equifax
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrResponseNotSigned = errors.New("response is not signed")
+	ErrInvalidSignature  = errors.New("invalid response signature")
+	ErrUntrustedSigner   = errors.New("response is signed by an untrusted certificate")
+)
+
+// Signer подписывает запрос к кредитному бюро и возвращает сообщение CMS (SignedData)
+// с вложенными подписанными данными.
+type Signer interface {
+	Sign(data []byte) ([]byte, error)
+}
+
+// ContextSigner - Signer, который прекращает подпись при отмене ctx. Клиент кредитного бюро
+// передает в SignContext контекст запроса, если подписчик реализует этот интерфейс.
+type ContextSigner interface {
+	Signer
+	SignContext(ctx context.Context, data []byte) ([]byte, error)
+}
+
+// Verifier проверяет подпись сообщения CMS, полученного от кредитного бюро.
+//
+// Ошибка возвращается, только если из сообщения невозможно извлечь данные. Неверная
+// подпись или подпись недоверенным сертификатом отражаются в SignatureInfo; клиент такой
+// ответ отклоняет (см. EquifaxCreditConfig.SetInsecureAcceptUnverified).
+type Verifier interface {
+	Verify(signed []byte) ([]byte, *SignatureInfo, error)
+}
+
+// SignatureInfo - результат проверки подписи ответа кредитного бюро.
+type SignatureInfo struct {
+	Verified    bool              // подпись верна и выполнена доверенным сертификатом Equifax
+	Subject     string            // субъект сертификата подписанта
+	SigningTime time.Time         // время подписи, если передано в подписанных атрибутах
+	Certificate *x509.Certificate // сертификат подписанта, если доступен
+	Err         error             // причина, по которой подпись не подтверждена
+}
+
+// TrustStore хранит сертификаты, которыми Equifax подписывает ответы.
+type TrustStore struct {
+	certs []*x509.Certificate
+}
+
+func NewTrustStore(certs ...*x509.Certificate) *TrustStore {
+	return &TrustStore{certs: certs}
+}
+
+// AddPEM добавляет в хранилище сертификаты в формате PEM.
+func (s *TrustStore) AddPEM(data []byte) error {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return err
+		}
+		s.certs = append(s.certs, cert)
+	}
+}
+
+func (s *TrustStore) Add(cert *x509.Certificate) {
+	s.certs = append(s.certs, cert)
+}
+
+// Contains сообщает, что cert в точности совпадает с одним из сертификатов хранилища.
+func (s *TrustStore) Contains(cert *x509.Certificate) bool {
+	if s == nil || cert == nil {
+		return false
+	}
+	for _, c := range s.certs {
+		if bytes.Equal(c.Raw, cert.Raw) {
+			return true
+		}
+	}
+	return false
+}
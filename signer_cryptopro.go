@@ -0,0 +1,120 @@
+//go:build !nocryptopro
+// +build !nocryptopro
+
+package equifax
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/l-vitaly/cryptopro"
+	"github.com/pkg/errors"
+)
+
+// CryptoProSigner подписывает запросы сертификатом из хранилища КриптоПро CSP.
+type CryptoProSigner struct {
+	Cert cryptopro.Cert
+}
+
+func (s *CryptoProSigner) Sign(data []byte) ([]byte, error) {
+	return s.SignContext(context.Background(), data)
+}
+
+// cryptoProChunk - размер порции данных, которые передаются CSP между проверками ctx.
+const cryptoProChunk = 64 * 1024
+
+// SignContext передает данные CSP порциями и прекращает подпись, если ctx отменен между
+// ними. Вызов CSP, который уже начался, не прерывается.
+func (s *CryptoProSigner) SignContext(ctx context.Context, data []byte) ([]byte, error) {
+	dest := new(bytes.Buffer)
+
+	msg, err := cryptopro.OpenToEncode(dest, cryptopro.EncodeOptions{
+		Signers: []cryptopro.Cert{s.Cert},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for len(data) > 0 {
+		if err = ctx.Err(); err != nil {
+			msg.Close()
+			return nil, err
+		}
+
+		n := len(data)
+		if n > cryptoProChunk {
+			n = cryptoProChunk
+		}
+		if _, err = msg.Write(data[:n]); err != nil {
+			msg.Close()
+			return nil, err
+		}
+		data = data[n:]
+	}
+
+	if err = ctx.Err(); err != nil {
+		msg.Close()
+		return nil, err
+	}
+	if err = msg.Close(); err != nil {
+		return nil, err
+	}
+
+	return dest.Bytes(), nil
+}
+
+// CryptoProVerifier проверяет подпись ответа средствами КриптоПро CSP. Подпись считается
+// подтвержденной, если она верна для одного из сертификатов Equifax в Certs. Сертификат
+// подписанта и время подписи берутся из сообщения.
+type CryptoProVerifier struct {
+	Certs []cryptopro.Cert
+}
+
+func (v *CryptoProVerifier) Verify(signed []byte) ([]byte, *SignatureInfo, error) {
+	respMsg, err := cryptopro.OpenToDecode(bytes.NewReader(signed))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cBuf := bytes.NewBuffer([]byte{})
+	_, err = io.Copy(cBuf, respMsg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info := &SignatureInfo{Err: ErrUntrustedSigner}
+	info.Certificate, info.SigningTime = pkcs7SignerDetails(signed)
+	if info.Certificate != nil {
+		info.Subject = info.Certificate.Subject.String()
+	}
+
+	for _, crt := range v.Certs {
+		err = respMsg.Verify(crt)
+		if err == nil {
+			info.Verified = true
+			info.Subject = crt.Info().SubjectStr()
+			info.Err = nil
+			break
+		}
+		info.Err = errors.Wrap(ErrInvalidSignature, err.Error())
+	}
+
+	return cBuf.Bytes(), info, nil
+}
+
+// NewEquifaxCredit создает клиент, подписывающий запросы сертификатом партнера crt.
+// Сертификат Equifax для проверки ответов задается через SetVerifier, до этого ответы
+// отклоняются; NewEquifaxCreditVerified принимает его сразу.
+func NewEquifaxCredit(url string, partnerID string, crt cryptopro.Cert, schema string, saveReq bool) CreditClient {
+	return NewEquifaxCreditSigner(url, partnerID, &CryptoProSigner{Cert: crt}, nil, schema, saveReq)
+}
+
+// NewEquifaxCreditVerified создает клиент, подписывающий запросы сертификатом партнера crt и
+// принимающий только ответы, подписанные сертификатом Equifax equifaxCrt.
+func NewEquifaxCreditVerified(
+	url string, partnerID string, crt cryptopro.Cert, equifaxCrt cryptopro.Cert, schema string, saveReq bool,
+) CreditClient {
+	verifier := &CryptoProVerifier{Certs: []cryptopro.Cert{equifaxCrt}}
+	return NewEquifaxCreditSigner(url, partnerID, &CryptoProSigner{Cert: crt}, verifier, schema, saveReq)
+}
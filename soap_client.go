@@ -2,11 +2,9 @@ package equifax
 
 import (
 	"bytes"
-	"crypto/tls"
+	"context"
 	"encoding/xml"
 	"io/ioutil"
-	"log"
-	"net"
 	"net/http"
 	"time"
 )
@@ -120,43 +118,92 @@ type BasicAuth struct {
 }
 
 type SOAPClient struct {
-	url       string
-	enableTLS bool
-	auth      *BasicAuth
-	header    interface{}
-	client    *http.Client
-	logger    Logger
+	url    string
+	auth   *BasicAuth
+	header interface{}
+	client *http.Client
+	logger Logger
+	retry  RetryPolicy
 }
 
+// responseChecker реализуют ответы, которые передают код ошибки в теле, а не через SOAP Fault.
+type responseChecker interface {
+	checkResponse() error
+}
+
+// NewSOAPClient создает клиент, проверяющий сертификат сервера по системным корневым
+// сертификатам. enableTLS не используется и оставлен для совместимости: прежде он отключал
+// проверку сертификата. Корневые и клиентские сертификаты задаются через NewSOAPClientTLS.
 func NewSOAPClient(url string, enableTLS bool, timeout time.Duration, auth *BasicAuth, logger Logger) *SOAPClient {
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: enableTLS,
-		},
-		Dial: func(network, addr string) (net.Conn, error) {
-			return net.DialTimeout(network, addr, timeout)
-		},
+	// конфигурация по умолчанию не загружает файлы, поэтому ошибки быть не может
+	client, _ := NewSOAPClientTLS(url, nil, timeout, auth, logger)
+	return client
+}
+
+// NewSOAPClientTLS создает клиент с настройками TLS tlsConfig; nil означает настройки по
+// умолчанию. Ошибка возвращается, если не удалось загрузить файлы сертификатов.
+func NewSOAPClientTLS(url string, tlsConfig *TLSConfig, timeout time.Duration, auth *BasicAuth, logger Logger) (*SOAPClient, error) {
+	// timeout покрывает весь обмен: соединение, отправку запроса и чтение ответа
+	return NewSOAPClientConfig(url, &TransportConfig{TLS: tlsConfig, Timeout: timeout}, auth, logger)
+}
+
+// NewSOAPClientConfig создает клиент с пулом keep-alive соединений по настройкам config;
+// nil означает настройки по умолчанию.
+func NewSOAPClientConfig(url string, config *TransportConfig, auth *BasicAuth, logger Logger) (*SOAPClient, error) {
+	client, err := config.Client()
+	if err != nil {
+		return nil, err
 	}
 
 	if logger == nil {
 		logger = new(NullLogger)
 	}
 
-	client := &http.Client{Transport: tr}
 	return &SOAPClient{
-		url:       url,
-		enableTLS: enableTLS,
-		auth:      auth,
-		client:    client,
-		logger:    logger,
+		url:    url,
+		auth:   auth,
+		client: client,
+		logger: logger,
+		retry:  NoRetry,
+	}, nil
+}
+
+// SetHTTPClient задает HTTP-клиент, например общий для нескольких SOAPClient.
+func (s *SOAPClient) SetHTTPClient(client *http.Client) {
+	if client == nil {
+		client = http.DefaultClient
 	}
+	s.client = client
 }
 
 func (s *SOAPClient) SetHeader(header interface{}) {
 	s.header = header
 }
 
+func (s *SOAPClient) SetRetryPolicy(policy RetryPolicy) {
+	if policy == nil {
+		policy = NoRetry
+	}
+	s.retry = policy
+}
+
 func (s *SOAPClient) Call(soapAction string, request, response interface{}) error {
+	return s.CallContext(context.Background(), soapAction, request, response)
+}
+
+// CallContext выполняет SOAP-вызов; отмена ctx или истечение его дедлайна прерывают HTTP-запрос.
+// Запрос считается неидемпотентным: повторяются только попытки, которые сервер гарантированно не принял.
+func (s *SOAPClient) CallContext(ctx context.Context, soapAction string, request, response interface{}) error {
+	return s.callContext(ctx, soapAction, request, response, false)
+}
+
+func (s *SOAPClient) callContext(ctx context.Context, soapAction string, request, response interface{}, idempotent bool) error {
+	return retry(ctx, s.retry, idempotent, s.logger, func() error {
+		return s.do(ctx, soapAction, request, response)
+	})
+}
+
+func (s *SOAPClient) do(ctx context.Context, soapAction string, request, response interface{}) error {
 	buffer, err := s.buildRequest(request)
 	if err != nil {
 		return err
@@ -168,6 +215,7 @@ func (s *SOAPClient) Call(soapAction string, request, response interface{}) erro
 	if err != nil {
 		return err
 	}
+	req = req.WithContext(ctx)
 	if s.auth != nil {
 		req.SetBasicAuth(s.auth.Login, s.auth.Password)
 	}
@@ -178,7 +226,6 @@ func (s *SOAPClient) Call(soapAction string, request, response interface{}) erro
 	}
 
 	req.Header.Set("User-Agent", "equifaxFraud-client/0.1")
-	req.Close = true
 
 	res, err := s.client.Do(req)
 	if err != nil {
@@ -195,14 +242,20 @@ func (s *SOAPClient) Call(soapAction string, request, response interface{}) erro
 
     s.logger.Log("equfax_response", string(rawBody))
 
+	// SOAP Fault возвращается с любым статусом, иначе статус вне 2xx - ошибка HTTPError
 	respEnvelope, err := s.makeResponse(rawBody, response)
+	if respEnvelope != nil && respEnvelope.Body.Fault != nil {
+		return respEnvelope.Body.Fault
+	}
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return newHTTPError(res)
+	}
 	if err != nil {
 		return err
 	}
 
-	fault := respEnvelope.Body.Fault
-	if fault != nil {
-		return fault
+	if checker, ok := response.(responseChecker); ok {
+		return checker.checkResponse()
 	}
 
 	return nil
@@ -240,8 +293,7 @@ func (s *SOAPClient) buildRequest(request interface{}) (*bytes.Buffer, error) {
 
 func (s *SOAPClient) makeResponse(rawBody []byte, response interface{}) (*SOAPEnvelopeResponse, error) {
 	if len(rawBody) == 0 {
-		log.Println("empty response")
-		return nil, nil
+		return nil, ErrEmptyResponse
 	}
 	respEnvelope := new(SOAPEnvelopeResponse)
 	respEnvelope.Body = SOAPBodyResponse{Content: response}
@@ -1,7 +1,9 @@
 package test
 
 import (
+	"bytes"
 	"io/ioutil"
+	"strings"
 	"testing"
 	"time"
 
@@ -17,16 +19,16 @@ func TestBatchFraud(t *testing.T) {
 	expected, err := bf.ToCSV([]*equifax.NewApplication{
 		{
 			ApplicationID:              "333000333",
-			ApplicationDate:            equifax.Time{time.Date(2013, 3, 5, 10, 0, 0, 0, time.Local)},
+			ApplicationDate:            equifax.Time{time.Date(2013, 3, 5, 10, 0, 0, 0, equifax.BureauLocation)},
 			LastName:                   "Бендер",
 			FirstName:                  "Остап",
 			MiddleName:                 "Ибрагимович",
-			Birthday:                   equifax.Date{time.Date(1970, 2, 1, 0, 0, 0, 0, time.Local)},
+			Birthday:                   equifax.Date{time.Date(1970, 2, 1, 0, 0, 0, 0, equifax.BureauLocation)},
 			Birthplace:                 "Калининград",
 			DocType:                    equifax.DocType1,
 			DocNo:                      "1111№222333",
 			DocPlace:                   "ОУФМС Ленинского района г. Калининград",
-			DocDate:                    equifax.Date{time.Date(2000, 2, 1, 0, 0, 0, 0, time.Local)},
+			DocDate:                    equifax.Date{time.Date(2000, 2, 1, 0, 0, 0, 0, equifax.BureauLocation)},
 			DocCode:                    "770045",
 			PastDocType:                equifax.DocType99,
 			Sex:                        equifax.SexType1,
@@ -53,7 +55,7 @@ func TestBatchFraud(t *testing.T) {
 			LaApartment:                "13",
 			LaYears:                    "12",
 			LaMonth:                    "5",
-			LaDate:                     equifax.Date{time.Date(2000, 10, 1, 0, 0, 0, 0, time.Local)},
+			LaDate:                     equifax.Date{time.Date(2000, 10, 1, 0, 0, 0, 0, equifax.BureauLocation)},
 			RaPhone:                    "0112111990",
 			RaCountry:                  equifax.CountryTypeRU,
 			RaIndex:                    "333444",
@@ -72,7 +74,7 @@ func TestBatchFraud(t *testing.T) {
 			Position:                   "Главный консультант",
 			EmploymentYear:             "5",
 			EmploymentMonth:            "5",
-			EmploymentDate:             equifax.Date{time.Date(2005, 10, 1, 0, 0, 0, 0, time.Local)},
+			EmploymentDate:             equifax.Date{time.Date(2005, 10, 1, 0, 0, 0, 0, equifax.BureauLocation)},
 			EmploymentINN:              "7712345678",
 			IncomeProof:                equifax.IncomeProofType1,
 			MonthlyIncome:              "32418",
@@ -134,3 +136,97 @@ func TestBatchFraud(t *testing.T) {
 
 	u.AssertFileEquals("./batch_expected.csv", "batch_actual.csv", "")
 }
+
+func TestBatchWriter(t *testing.T) {
+	u := gounit.New(t)
+
+	apps := []*equifax.NewApplication{
+		{ApplicationID: "1", LastName: "Иванов", FirstName: "Иван"},
+		{ApplicationID: "2", LastName: "Петров", FirstName: "Петр", EmployerName: `ООО "Ромашка"`},
+	}
+
+	expected, err := equifax.NewBatchFraud().ToCSV(apps)
+	u.AssertNotError(err, "To CSV")
+
+	ch := make(chan *equifax.NewApplication)
+	go func() {
+		defer close(ch)
+		for _, app := range apps {
+			ch <- app
+		}
+	}()
+
+	buffer := new(bytes.Buffer)
+	u.AssertNotError(equifax.NewBatchWriter(buffer).WriteChan(ch), "Write Chan")
+	if buffer.String() != expected {
+		t.Fatalf("streamed batch differs from ToCSV:\n%s\n%s", buffer.String(), expected)
+	}
+
+	buffer.Reset()
+	w := equifax.NewBatchWriter(buffer)
+	w.Comma = ';'
+	u.AssertNotError(w.WriteAll(apps[:1]), "Write All")
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "applicationid;applicationdate;") || !strings.HasPrefix(lines[1], "1;") {
+		t.Fatalf("unexpected batch with custom comma: %q", buffer.String())
+	}
+}
+
+func TestBatchReader(t *testing.T) {
+	u := gounit.New(t)
+
+	bf := equifax.NewBatchFraud()
+	apps := []*equifax.NewApplication{
+		{
+			ApplicationID:     "1",
+			ApplicationDate:   equifax.Time{time.Date(2013, 3, 5, 10, 0, 0, 0, equifax.BureauLocation)},
+			LastName:          "Бендер",
+			FirstName:         "Остап",
+			Birthday:          equifax.Date{time.Date(1970, 2, 1, 0, 0, 0, 0, equifax.BureauLocation)},
+			DocType:           equifax.DocType1,
+			Citizenship:       equifax.CountryTypeRU,
+			EmployerName:      `ООО "Хорошие связи"`,
+			ApplicantTypeNum:  1,
+			ApplicationStatus: equifax.ApplicationStatusType9,
+		},
+		{ApplicationID: "2", LastName: "Иванов", FirstName: "Иван"},
+	}
+
+	data, err := bf.Encode(apps)
+	u.AssertNotError(err, "Encode")
+
+	decoded, err := bf.Decode(data)
+	u.AssertNotError(err, "Decode")
+	if len(decoded) != 2 || !decoded[0].ApplicationDate.Equal(apps[0].ApplicationDate.Time) || decoded[0].EmployerName != apps[0].EmployerName {
+		t.Fatalf("unexpected applications: %+v", decoded)
+	}
+
+	reencoded, err := bf.Encode(decoded)
+	u.AssertNotError(err, "Encode")
+	if !bytes.Equal(reencoded, data) {
+		t.Fatalf("round trip mismatch:\n%s\n%s", reencoded, data)
+	}
+
+	decoded, err = bf.FromCSV("applicationid\tmiddlename\tdoctype\tbirthday\n" +
+		"1\tИванович\t1\t01.02.1970\n" +
+		"2\tПетрович\tx\t01.02.1970\n" +
+		"3\tСидорович\t1\t31.02.1970\n" +
+		"4\tEMPTY\t\t\n")
+	if len(decoded) != 2 || decoded[1].ApplicationID != "4" || decoded[1].MiddleName != "" {
+		t.Fatalf("unexpected applications: %+v", decoded)
+	}
+
+	errs, ok := err.(equifax.BatchErrors)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("expected 2 row errors, got %v", err)
+	}
+	if errs[0].Line != 3 || errs[0].Column != "doctype" || errs[1].Line != 4 || errs[1].Column != "birthday" {
+		t.Fatalf("unexpected row errors: %v", err)
+	}
+
+	_, err = bf.FromCSV("applicationid\tunknown\n1\t2\n")
+	if err == nil {
+		t.Fatal("expected unknown column error")
+	}
+}
@@ -0,0 +1,140 @@
+package test
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/l-vitaly/equifax"
+	"github.com/l-vitaly/gounit"
+)
+
+func testLegalEntityRequest() *equifax.LegalEntityRequest {
+	r := testCreditRequest()
+	return &equifax.LegalEntityRequest{
+		Num:          1,
+		Type:         "30033",
+		DateOfReport: equifax.Date{time.Now()},
+		Reason:       equifax.ReasonType1,
+		Company: equifax.LegalEntity{
+			FullName:  "ПАО СБЕРБАНК",
+			ShortName: "СБЕРБАНК",
+			Resident:  equifax.ResidentType1,
+			Phone:     "4955005550",
+			INN:       "7707083893",
+			EGRN:      "1027700132195",
+		},
+		Application: equifax.ApplicationLegalEntity{
+			State:         equifax.CompanyStateType0,
+			Size:          equifax.CompanySizeType4,
+			Area:          equifax.CompanyAreaType06,
+			BeginningDate: equifax.Date{time.Date(1991, 6, 20, 0, 0, 0, 0, time.UTC)},
+		},
+		Consent:       equifax.ConsentType1,
+		AdmCodeInForm: equifax.AdmCodeInFormType1,
+		ConsentDate:   equifax.Date{time.Now()},
+		AddressReg:    r.AddressReg,
+	}
+}
+
+func TestLegalEntityBuild(t *testing.T) {
+	u := gounit.New(t)
+
+	req, err := testLegalEntityRequest().Build()
+	u.AssertNotError(err, "Build")
+	if req.LegalEntity.RegCountry != equifax.CountryTypeRU || req.Individual != nil {
+		t.Fatalf("unexpected commercial: %+v", req.LegalEntity)
+	}
+	if req.Application.Consent != equifax.ConsentType1 || req.Application.LegalEntity.Area != equifax.CompanyAreaType06 {
+		t.Fatalf("unexpected application: %+v", req.Application)
+	}
+	if req.AddressFact == nil || req.AddressFact.City != req.AddressReg.City {
+		t.Fatalf("unexpected addr_fact: %+v", req.AddressFact)
+	}
+
+	r := testLegalEntityRequest()
+	r.Company.Resident = equifax.ResidentType0
+	r.Company.RegCountry = "DE"
+	r.Company.INN = ""
+	r.Company.EGRN = "HRB86891"
+	_, err = r.Build()
+	u.AssertNotError(err, "Build Non-Resident")
+
+	r = testLegalEntityRequest()
+	r.Application.Area = equifax.CompanyAreaType98
+	r.Application.BeginningDate = equifax.Date{}
+	_, err = r.Build()
+	assertCreditFields(t, err, equifax.ResponseCodeType12,
+		"application.commercial.company_area_text", "application.commercial.company_beginning_date")
+
+	// согласие и информирование не выводятся из даты согласия
+	r = testLegalEntityRequest()
+	r.Consent = equifax.ConsentType0
+	r.AdmCodeInForm = equifax.AdmCodeInFormType0
+	_, err = r.Build()
+	assertCreditFields(t, err, equifax.ResponseCodeType30, "application.consent", "application.admcode_inform")
+}
+
+func TestLegalEntityValidate(t *testing.T) {
+	c := testLegalEntityRequest().Company
+	c.RegCountry = "DE"
+	c.INN = "7707083894"
+	c.EGRN = ""
+	err := c.Validate()
+	assertCreditFields(t, err, equifax.ResponseCodeType12, "commercial.regcountry", "commercial.inn", "commercial.egrn")
+	if !errors.Is(err, equifax.ErrInvalidRequestXML) {
+		t.Fatalf("expected ErrInvalidRequestXML, got %v", err)
+	}
+
+	c = testLegalEntityRequest().Company
+	c.RegCountry = equifax.CountryTypeRU
+	c.INN = "770708389"
+	c.EGRN = "1027700132194"
+	err = c.Validate()
+	assertCreditFields(t, err, equifax.ResponseCodeType34, "commercial.inn", "commercial.egrn")
+	if !errors.Is(err, equifax.ErrLegalEntityIDMissing) {
+		t.Fatalf("expected ErrLegalEntityIDMissing, got %v", err)
+	}
+
+	c = testLegalEntityRequest().Company
+	c.Resident = equifax.ResidentType0
+	c.RegCountry = equifax.CountryTypeRU
+	assertCreditFields(t, c.Validate(), equifax.ResponseCodeType12, "commercial.regcountry")
+
+	r := testCreditRequest()
+	r.Application.LegalEntity = &testLegalEntityRequest().Application
+	assertCreditFields(t, r.Validate(), equifax.ResponseCodeType12, "application.commercial")
+}
+
+func TestReportLegalEntity(t *testing.T) {
+	u := gounit.New(t)
+
+	var resp *equifax.CreditResponse
+	err := xml.Unmarshal([]byte(`<?xml version="1.0" encoding="utf-8"?>
+<bki_response version="3.4" partnerid="90J">
+<response num="1">
+<responsecode>1</responsecode>
+<title_part>
+<commercial>
+<fullname>ПАО СБЕРБАНК</fullname>
+<resident>1</resident>
+<regcountry>RU</regcountry>
+<inn>7707083893</inn>
+<egrn>1027700132195</egrn>
+</commercial>
+</title_part>
+</response>
+</bki_response>`), &resp)
+	u.AssertNotError(err, "Unmarshal Report")
+
+	title := resp.Response.TitlePart
+	if title.Individual != nil || title.LegalEntity == nil {
+		t.Fatalf("unexpected title part: %+v", title)
+	}
+	c := title.LegalEntity
+	if c.FullName != "ПАО СБЕРБАНК" || c.Resident != equifax.ResidentType1 || c.RegCountry != equifax.CountryTypeRU ||
+		c.INN != "7707083893" || c.EGRN != "1027700132195" {
+		t.Fatalf("unexpected commercial: %+v", c)
+	}
+}
@@ -0,0 +1,138 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/l-vitaly/equifax"
+	"github.com/l-vitaly/equifax/equifaxtest"
+	"github.com/l-vitaly/gounit"
+)
+
+// creditPackager - пакетные вызовы клиента, которые не входят в CreditClient.
+type creditPackager interface {
+	GetPackage(r []*equifax.CreditRequest) ([]*equifax.CreditPackageResult, error)
+}
+
+func TestCreditPackage(t *testing.T) {
+	u := gounit.New(t)
+
+	srv := creditServer(t, testSchema)
+	defer srv.Close()
+	srv.Enqueue(
+		equifaxtest.CreditReply{Report: loadReportBytes(t)},
+		equifaxtest.CreditReply{Code: equifax.ResponseCodeType3},
+		equifaxtest.CreditReply{Code: equifax.ResponseCodeType30, Text: "не дано согласие"},
+	)
+
+	verifier := &equifax.PKCS7Verifier{Trust: equifax.NewTrustStore(srv.Signer.Certificate)}
+	c := equifax.NewEquifaxCreditSigner(srv.URL, "90J", testSigner(t, "partner"), verifier, testSchema, false)
+	c.SetValidation(true)
+	p := c.(creditPackager)
+
+	reqs := make([]*equifax.CreditRequest, 4)
+	for i := range reqs {
+		reqs[i] = testCreditRequest()
+		reqs[i].Num = 10 + i
+	}
+	reqs[2].AddressFact = nil
+
+	results, err := p.GetPackage(reqs)
+	u.AssertNotError(err, "Get Package")
+	if len(results) != len(reqs) {
+		t.Fatalf("expected %d results, got %d", len(reqs), len(results))
+	}
+
+	for i, res := range results {
+		if res.Request != reqs[i] {
+			t.Fatalf("result %d is out of order", i)
+		}
+	}
+
+	if results[0].Err != nil || len(results[0].Response.BasePart.Data) == 0 || results[0].Response.Num != "10" {
+		t.Fatalf("unexpected result 0: %+v", results[0])
+	}
+	if !results[0].Signature.Verified || results[0].Package == "" || results[0].Package != results[3].Package {
+		t.Fatalf("unexpected package: %+v", results[0])
+	}
+	if results[1].Err != nil || results[1].Response.Code != equifax.ResponseCodeType3 {
+		t.Fatalf("unexpected result 1: %+v", results[1])
+	}
+
+	var verr equifax.CreditValidationError
+	if !errors.As(results[2].Err, &verr) || results[2].Response != nil || results[2].Package != "" {
+		t.Fatalf("expected validation error, got %+v", results[2])
+	}
+	if !errors.Is(results[3].Err, equifax.ErrConsentMissing) {
+		t.Fatalf("expected ErrConsentMissing, got %v", results[3].Err)
+	}
+
+	if got := srv.Requests(); len(got) != 3 || got[2].Num != 13 {
+		t.Fatalf("unexpected requests: %+v", got)
+	}
+}
+
+func TestCreditPackageChunks(t *testing.T) {
+	u := gounit.New(t)
+
+	srv := creditServer(t, "")
+	defer srv.Close()
+
+	verifier := &equifax.PKCS7Verifier{Trust: equifax.NewTrustStore(srv.Signer.Certificate)}
+	p := equifax.NewEquifaxCreditSigner(srv.URL, "90J", testSigner(t, "partner"), verifier, "", false).(creditPackager)
+
+	reqs := make([]*equifax.CreditRequest, equifax.MaxPackageRecords+1)
+	for i := range reqs {
+		reqs[i] = &equifax.CreditRequest{Num: i + 1, Type: "30033"}
+	}
+
+	results, err := p.GetPackage(reqs)
+	u.AssertNotError(err, "Get Package")
+
+	packages := make(map[string]int)
+	for _, res := range results {
+		if res.Err != nil || res.Response.Num != strconv.Itoa(res.Request.Num) {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+		packages[res.Package]++
+	}
+	if len(packages) != 2 || packages[results[0].Package] != equifax.MaxPackageRecords {
+		t.Fatalf("unexpected packages: %v", packages)
+	}
+	if len(srv.Requests()) != len(reqs) {
+		t.Fatalf("expected %d requests, got %d", len(reqs), len(srv.Requests()))
+	}
+
+	_, err = p.GetPackage([]*equifax.CreditRequest{{Num: 1, Type: "30033"}, {Num: 1, Type: "30033"}})
+	if !errors.Is(err, equifax.ErrPackageDuplicateNum) {
+		t.Fatalf("expected ErrPackageDuplicateNum, got %v", err)
+	}
+}
+
+func TestCreditPackageNoRetry(t *testing.T) {
+	srv := creditServer(t, "")
+	defer srv.Close()
+	srv.Enqueue(equifaxtest.CreditReply{HTTPStatus: http.StatusServiceUnavailable})
+
+	verifier := &equifax.PKCS7Verifier{Trust: equifax.NewTrustStore(srv.Signer.Certificate)}
+	c := equifax.NewEquifaxCreditSigner(srv.URL, "90J", testSigner(t, "partner"), verifier, "", false)
+	c.SetRetryPolicy(testBackoff())
+
+	reqs := []*equifax.CreditRequest{{Num: 1, Type: "30033"}, {Num: 2, Type: "30033"}}
+	results, err := c.(creditPackager).GetPackage(reqs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var herr *equifax.HTTPError
+	for _, res := range results {
+		if !errors.As(res.Err, &herr) || herr.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("expected HTTPError, got %v", res.Err)
+		}
+	}
+	if len(srv.Requests()) != len(reqs) {
+		t.Fatalf("package must be posted once, got %d requests", len(srv.Requests()))
+	}
+}
@@ -0,0 +1,237 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/l-vitaly/equifax"
+	"github.com/l-vitaly/equifax/equifaxtest"
+	"github.com/l-vitaly/gounit"
+)
+
+func testSigner(t *testing.T, cn string) *equifax.PKCS7Signer {
+	signer, err := equifaxtest.NewSigner(cn)
+	gounit.New(t).AssertNotError(err, "New Signer")
+	return signer
+}
+
+func creditServer(t *testing.T, schema string) *equifaxtest.CreditServer {
+	srv, err := equifaxtest.NewCreditServer("90J", schema)
+	gounit.New(t).AssertNotError(err, "New Credit Server")
+	return srv
+}
+
+func loadReportBytes(t *testing.T) []byte {
+	report, err := ioutil.ReadFile("./report.xml")
+	gounit.New(t).AssertNotError(err, "Read Report")
+	return report
+}
+
+func testCreditRequest() *equifax.CreditRequest {
+	return &equifax.CreditRequest{
+		Num:          1,
+		Type:         "30033",
+		DateOfReport: equifax.Date{time.Now()},
+		Reason:       1,
+		Individual: &equifax.Individual{
+			FirstName:  "СЕРГЕЙ",
+			LastName:   "СЕРГЕЕВ",
+			MiddleName: "СЕРГЕЕВИЧ",
+			Gender:     equifax.GenderType1,
+			Birthday:   equifax.Date{time.Date(1975, 1, 20, 0, 0, 0, 0, time.UTC)},
+			Birthplace: "МОСКВА",
+			IdentityDocument: &equifax.IdentityDocument{
+				DocType:  equifax.DocType1,
+				DocNO:    "2000000000",
+				DocDate:  equifax.Date{time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)},
+				DocPlace: "ОВД УЛЬЯНОВСКА",
+			},
+		},
+		Application: &equifax.Application{
+			Consent:        equifax.ConsentType1,
+			ConsentDate:    equifax.Date{time.Now()},
+			ConsentEndDate: equifax.Date{time.Now().Add(time.Hour * 24)},
+			AdmCodeInForm:  equifax.AdmCodeInFormType1,
+		},
+		AddressFact: &equifax.AddressFact{
+			Index:   "000000",
+			Country: equifax.CountryTypeRU,
+			City:    "МОСКВА",
+			Region:  equifax.RegionType00,
+			Street:  "6 КВАРТАЛ",
+			House:   "17",
+			Flat:    "48",
+		},
+		AddressReg: &equifax.AddressReg{
+			Index:   "000000",
+			Country: equifax.CountryTypeRU,
+			City:    "МОСКВА",
+			Region:  equifax.RegionType00,
+			Street:  "6 КВАРТАЛ",
+			House:   "17",
+			Flat:    "48",
+		},
+	}
+}
+
+func TestCreditSchema(t *testing.T) {
+	u := gounit.New(t)
+
+	srv := creditServer(t, testSchema)
+	defer srv.Close()
+	srv.Enqueue(equifaxtest.CreditReply{Report: loadReportBytes(t)})
+
+	verifier := &equifax.PKCS7Verifier{Trust: equifax.NewTrustStore(srv.Signer.Certificate)}
+	c := equifax.NewEquifaxCreditSigner(srv.URL, "90J", testSigner(t, "partner"), verifier, testSchema, false)
+
+	resp, err := c.Get(testCreditRequest())
+	u.AssertNotError(err, "Get Credit History")
+	u.AssertGreaterThan(0, len(resp.Response.BasePart.Data), "BasePart")
+
+	reqs := srv.Requests()
+	if len(reqs) != 1 || reqs[0].Individual.LastName != "СЕРГЕЕВ" || reqs[0].Individual.Birthday.Year() != 1975 {
+		t.Fatalf("unexpected requests: %+v", reqs)
+	}
+}
+
+func TestCreditSigner(t *testing.T) {
+	u := gounit.New(t)
+
+	srv := creditServer(t, "")
+	defer srv.Close()
+	srv.Enqueue(equifaxtest.CreditReply{Report: loadReportBytes(t)})
+
+	verifier := &equifax.PKCS7Verifier{Trust: equifax.NewTrustStore(srv.Signer.Certificate)}
+	c := equifax.NewEquifaxCreditSigner(srv.URL, "90J", testSigner(t, "partner"), verifier, "", false)
+
+	resp, err := c.Get(&equifax.CreditRequest{Num: 1, Type: "30033"})
+	u.AssertNotError(err, "Get Credit History")
+
+	if !resp.Signature.Verified || resp.Signature.Subject != "CN=equifax" || resp.Signature.SigningTime.IsZero() {
+		t.Fatalf("unexpected signature: %+v", resp.Signature)
+	}
+
+	if resp.Response.Code != equifax.ResponseCodeType1 || len(resp.Response.BasePart.AddressReg) != 1 {
+		t.Fatalf("unexpected response: %+v", resp.Response)
+	}
+	if resp.Response.TitlePart.Individual.LastName != "СЕРГЕЕВ" {
+		t.Fatalf("unexpected subject: %+v", resp.Response.TitlePart.Individual)
+	}
+
+	if reqs := srv.Requests(); len(reqs) != 1 || reqs[0].Type != "30033" {
+		t.Fatalf("unexpected requests: %+v", reqs)
+	}
+}
+
+func TestCreditStrictVerification(t *testing.T) {
+	u := gounit.New(t)
+
+	srv := creditServer(t, "")
+	defer srv.Close()
+	srv.Signer = testSigner(t, "impostor")
+	srv.Enqueue(
+		equifaxtest.CreditReply{Report: loadReportBytes(t)},
+		equifaxtest.CreditReply{Report: loadReportBytes(t), Unsigned: true},
+		equifaxtest.CreditReply{Report: loadReportBytes(t)},
+		equifaxtest.CreditReply{Report: loadReportBytes(t), Unsigned: true},
+	)
+
+	verifier := &equifax.PKCS7Verifier{Trust: equifax.NewTrustStore(testSigner(t, "equifax").Certificate)}
+	c := equifax.NewEquifaxCreditSigner(srv.URL, "90J", testSigner(t, "partner"), verifier, "", false)
+
+	_, err := c.Get(&equifax.CreditRequest{Num: 1, Type: "30033"})
+	if err != equifax.ErrUntrustedSigner {
+		t.Fatalf("expected ErrUntrustedSigner, got %v", err)
+	}
+
+	_, err = c.Get(&equifax.CreditRequest{Num: 1, Type: "30033"})
+	if err != equifax.ErrResponseNotSigned {
+		t.Fatalf("expected ErrResponseNotSigned, got %v", err)
+	}
+
+	c.SetInsecureAcceptUnverified(true)
+	resp, err := c.Get(&equifax.CreditRequest{Num: 1, Type: "30033"})
+	u.AssertNotError(err, "Get Credit History")
+	if resp.Signature.Verified || resp.Signature.Err != equifax.ErrUntrustedSigner || resp.Signature.Certificate == nil {
+		t.Fatalf("unexpected signature: %+v", resp.Signature)
+	}
+
+	resp, err = c.Get(&equifax.CreditRequest{Num: 1, Type: "30033"})
+	u.AssertNotError(err, "Get Credit History")
+	if resp.Signature.Verified || resp.Signature.Err != equifax.ErrResponseNotSigned {
+		t.Fatalf("unexpected signature: %+v", resp.Signature)
+	}
+}
+
+func TestCreditResponseCode(t *testing.T) {
+	srv := creditServer(t, "")
+	defer srv.Close()
+	srv.Enqueue(equifaxtest.CreditReply{Code: equifax.ResponseCodeType30})
+
+	partner := testSigner(t, "partner")
+	verifier := &equifax.PKCS7Verifier{Trust: equifax.NewTrustStore(srv.Signer.Certificate)}
+	c := equifax.NewEquifaxCreditSigner(srv.URL, "90J", partner, verifier, "", false)
+
+	_, err := c.Get(&equifax.CreditRequest{Num: 1, Type: "30033"})
+	if !errors.Is(err, equifax.ErrConsentMissing) {
+		t.Fatalf("expected ErrConsentMissing, got %v", err)
+	}
+
+	resp, err := c.Get(&equifax.CreditRequest{Num: 2, Type: "30033"})
+	gounit.New(t).AssertNotError(err, "Get Credit History")
+	if resp.Response.Code != equifax.ResponseCodeType3 || resp.Response.Num != "2" {
+		t.Fatalf("unexpected response: %+v", resp.Response)
+	}
+
+	srv.SetVerifier(&equifax.PKCS7Verifier{Trust: equifax.NewTrustStore(testSigner(t, "other").Certificate)})
+	_, err = c.Get(&equifax.CreditRequest{Num: 3, Type: "30033"})
+	if !errors.Is(err, equifax.ErrSignatureMismatch) {
+		t.Fatalf("expected ErrSignatureMismatch, got %v", err)
+	}
+
+	srv.SetVerifier(nil)
+	c = equifax.NewEquifaxCreditSigner(srv.URL, "91J", partner, verifier, "", false)
+	_, err = c.Get(&equifax.CreditRequest{Num: 4, Type: "30033"})
+	if !errors.Is(err, equifax.ErrPartnerNotFound) {
+		t.Fatalf("expected ErrPartnerNotFound, got %v", err)
+	}
+}
+
+// contextSigner ждет отмены ctx и сообщает, что подпись прекращена.
+type contextSigner struct {
+	*equifax.PKCS7Signer
+	stopped chan error
+}
+
+func (s *contextSigner) SignContext(ctx context.Context, data []byte) ([]byte, error) {
+	<-ctx.Done()
+	s.stopped <- ctx.Err()
+	return nil, ctx.Err()
+}
+
+func TestCreditContextSigner(t *testing.T) {
+	srv := creditServer(t, "")
+	defer srv.Close()
+
+	signer := &contextSigner{PKCS7Signer: testSigner(t, "partner"), stopped: make(chan error, 1)}
+	verifier := &equifax.PKCS7Verifier{Trust: equifax.NewTrustStore(srv.Signer.Certificate)}
+	c := equifax.NewEquifaxCreditSigner(srv.URL, "90J", signer, verifier, "", false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := c.GetContext(ctx, testCreditRequest())
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	// SignContext получил ctx запроса и завершился вместе с ним
+	if err = <-signer.stopped; err != context.DeadlineExceeded {
+		t.Fatalf("expected signer to stop with context.DeadlineExceeded, got %v", err)
+	}
+	if len(srv.Requests()) != 0 {
+		t.Fatal("request is sent after cancellation")
+	}
+}
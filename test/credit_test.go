@@ -1,11 +1,14 @@
+//go:build !nocryptopro
+// +build !nocryptopro
+
 package test
 
 import (
 	"testing"
-	"time"
 
 	"github.com/l-vitaly/cryptopro"
 	"github.com/l-vitaly/equifax"
+	"github.com/l-vitaly/equifax/equifaxtest"
 	"github.com/l-vitaly/gounit"
 )
 
@@ -20,54 +23,16 @@ func TestCredit(t *testing.T) {
 	defer crt.Close()
 	u.AssertNotError(err, "Get Cert")
 
-	c := equifax.NewEquifaxCredit("http://10.130.1.2/xml.php", "90J", crt, "./schema.xml", false)
+	srv := creditServer(t, testSchema)
+	defer srv.Close()
+	srv.SetVerifier(&equifax.CryptoProVerifier{Certs: []cryptopro.Cert{crt}})
+	srv.Enqueue(equifaxtest.CreditReply{Report: loadReportBytes(t), Unsigned: true})
 
-	req := &equifax.CreditRequest{
-		Num:          1,
-		Type:         "30033",
-		DateOfReport: equifax.Date{time.Now()},
-		Reason:       1,
-		Individual: &equifax.Individual{
-			FirstName:  "СЕРГЕЙ",
-			LastName:   "СЕРГЕЕВ",
-			MiddleName: "СЕРГЕЕВИЧ",
-			Gender:     equifax.GenderType1,
-			Birthday:   equifax.Date{time.Date(1975, 1, 20, 0, 0, 0, 0, time.UTC)},
-			Birthplace: "МОСКВА",
-			IdentityDocument: &equifax.IdentityDocument{
-				DocType:  equifax.DocType1,
-				DocNO:    "2000000000",
-				DocDate:  equifax.Date{time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)},
-				DocPlace: "ОВД УЛЬЯНОВСКА",
-			},
-		},
-		Application: &equifax.Application{
-			Consent:        equifax.ConsentType1,
-			ConsentDate:    equifax.Date{time.Now()},
-			ConsentEndDate: equifax.Date{time.Now().Add(time.Hour * 24)},
-			AdmCodeInForm:  equifax.AdmCodeInFormType1,
-		},
-		AddressFact: &equifax.AddressFact{
-			Index:   "000000",
-			Country: equifax.CountryTypeRU,
-			City:    "МОСКВА",
-			Region:  equifax.RegionType00,
-			Street:  "6 КВАРТАЛ",
-			House:   "17",
-			Flat:    "48",
-		},
-		AddressReg: &equifax.AddressReg{
-			Index:   "000000",
-			Country: equifax.CountryTypeRU,
-			City:    "МОСКВАs",
-			Region:  equifax.RegionType00,
-			Street:  "6 КВАРТАЛ",
-			House:   "17",
-			Flat:    "48",
-		},
-	}
+	c := equifax.NewEquifaxCreditVerified(srv.URL, "90J", crt, crt, testSchema, false)
+	// ответ тестового бюро не подписан сертификатом Equifax
+	c.SetInsecureAcceptUnverified(true)
 
-	resp, err := c.Get(req)
+	resp, err := c.Get(testCreditRequest())
 
 	u.AssertNotError(err, "Get Credit History")
 	u.AssertContains(resp.Response.Code, []equifax.ResponseCode{
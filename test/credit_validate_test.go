@@ -0,0 +1,113 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/l-vitaly/equifax"
+	"github.com/l-vitaly/gounit"
+)
+
+func TestCreditValidate(t *testing.T) {
+	u := gounit.New(t)
+
+	u.AssertNotError(testCreditRequest().Validate(), "Validate")
+	u.AssertNotError(equifax.ValidateCreditRequest("90J", testCreditRequest()), "Validate With Partner")
+
+	r := testCreditRequest()
+	r.Num = -1
+	r.Type = ""
+	r.Individual.LastName = "СЕРГЕЕВ!"
+	r.Individual.MiddleName = "СЕРГЕЕВИЧ1"
+	r.Individual.INN = "1234"
+	r.Individual.IdentityDocument.DocType = 15
+	r.AddressReg = nil
+	r.AddressFact.Index = "12345"
+	r.AddressFact.AddrTotal = "МОСКВА, 6 КВАРТАЛ, 17-48"
+
+	err := equifax.ValidateCreditRequest("90JX", r)
+	assertCreditFields(t, err, equifax.ResponseCodeType12,
+		"partnerid", "num", "type", "private.lastname", "private.middlename", "private.doc.doctype",
+		"private.inn", "addr_reg", "addr_fact.index", "addr_fact.addr_fact_total")
+	if !errors.Is(err, equifax.ErrInvalidRequestXML) {
+		t.Fatalf("expected ErrInvalidRequestXML, got %v", err)
+	}
+
+	r = testCreditRequest()
+	r.AddressFact = &equifax.AddressFact{Index: "000000", AddrTotal: "МОСКВА, 6 КВАРТАЛ, 17-48"}
+	r.Individual.MiddleName = ""
+	u.AssertNotError(r.Validate(), "Validate Address Total")
+}
+
+func TestCreditValidateConsent(t *testing.T) {
+	r := testCreditRequest()
+	r.Application.Consent = equifax.ConsentType0
+	r.Application.AdmCodeInForm = equifax.AdmCodeInFormType0
+	err := r.Validate()
+	assertCreditFields(t, err, equifax.ResponseCodeType30, "application.consent", "application.admcode_inform")
+	if !errors.Is(err, equifax.ErrConsentMissing) {
+		t.Fatalf("expected ErrConsentMissing, got %v", err)
+	}
+
+	r = testCreditRequest()
+	r.Application.ConsentDate = equifax.Date{time.Now().AddDate(0, 0, 2)}
+	err = r.Validate()
+	assertCreditFields(t, err, equifax.ResponseCodeType31, "application.consentdate")
+	if !errors.Is(err, equifax.ErrInvalidConsentDate) {
+		t.Fatalf("expected ErrInvalidConsentDate, got %v", err)
+	}
+
+	r = testCreditRequest()
+	r.Application = nil
+	assertCreditFields(t, r.Validate(), equifax.ResponseCodeType32, "application")
+
+	r = testCreditRequest()
+	r.Reason = equifax.ReasonType9
+	assertCreditFields(t, r.Validate(), equifax.ResponseCodeType37, "reason_text")
+}
+
+func TestCreditValidation(t *testing.T) {
+	srv := creditServer(t, "")
+	defer srv.Close()
+
+	c := equifax.NewEquifaxCreditSigner(srv.URL, "90J", testSigner(t, "partner"), nil, "", false)
+	c.SetValidation(true)
+
+	r := testCreditRequest()
+	r.AddressFact = nil
+	_, err := c.Get(r)
+
+	var verr equifax.CreditValidationError
+	if !errors.As(err, &verr) || verr.Code() != equifax.ResponseCodeType12 {
+		t.Fatalf("expected CreditValidationError, got %v", err)
+	}
+	if len(srv.Requests()) != 0 {
+		t.Fatalf("invalid request was sent")
+	}
+}
+
+func assertCreditFields(t *testing.T, err error, code equifax.ResponseCode, fields ...string) {
+	t.Helper()
+
+	var verr equifax.CreditValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected CreditValidationError, got %v", err)
+	}
+	if verr.Code() != code {
+		t.Fatalf("expected code %d, got %d: %v", code, verr.Code(), err)
+	}
+
+	got := make(map[string]bool, len(verr))
+	for _, e := range verr {
+		got[e.Field] = true
+	}
+	if len(got) != len(fields) {
+		t.Fatalf("expected errors in %v, got %v", fields, err)
+	}
+	for _, f := range fields {
+		if !got[f] {
+			t.Fatalf("expected error in %s, got %v", f, err)
+		}
+	}
+}
@@ -1,23 +1,138 @@
 package test
 
 import (
+	"context"
+	"encoding/xml"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/l-vitaly/equifax"
+	"github.com/l-vitaly/equifax/equifaxtest"
+	"github.com/l-vitaly/gounit"
 )
 
 func TestClient(t *testing.T) {
-	c := equifax.NewEquifaxFraud(
-		"http://10.130.11.151/soap/bank",
-		"",
-		"",
-		"",
-		false,
-		15*time.Second,
-		nil,
-		nil,
+	u := gounit.New(t)
+
+	srv := equifaxtest.NewFraudServer()
+	defer srv.Close()
+
+	c := equifax.NewEquifaxFraud(srv.URL, "login", "password", "partner", false, 15*time.Second, nil, nil)
+
+	_, err := c.NewApplication(&equifax.NewApplication{ApplicationID: "1", LastName: "Иванов", FirstName: "Иван"})
+	u.AssertNotError(err, "New Application")
+
+	_, err = c.NewApplication(&equifax.NewApplication{ApplicationID: "1", LastName: "Иванов", FirstName: "Иван"})
+	if !equifax.IsDuplicate(err) {
+		t.Fatalf("expected duplicate error, got %v", err)
+	}
+
+	_, err = c.OutputVector(&equifax.OutputVector{ApplicationID: "1"})
+	u.AssertNotError(err, "Output Vector")
+
+	_, err = c.UpdateCreditStatus(&equifax.UpdateCreditStatus{ApplicationID: "1", ApplicationStatus: equifax.ApplicationStatusType1})
+	u.AssertNotError(err, "Update Credit Status")
+
+	_, err = c.UpdateCreditStatus(&equifax.UpdateCreditStatus{ApplicationID: "1", ApplicationStatus: equifax.ApplicationStatusType1})
+	if !equifax.IsDuplicate(err) {
+		t.Fatalf("expected repeated status error, got %v", err)
+	}
+
+	_, err = c.UpdateFraudStatus(&equifax.UpdateFraudStatus{ApplicationID: "1", ApplicationFraudStatus: equifax.ApplicationFraudStatusType8})
+	if statusErr, ok := err.(*equifax.StatusError); !ok || statusErr.Status != equifax.StatusType27 {
+		t.Fatalf("expected status 27, got %v", err)
+	}
+
+	_, err = c.UpdateDefaultStatus(&equifax.UpdateDefaultStatus{ApplicationID: "1", DefaultStatus: equifax.DefaultStatusType3})
+	u.AssertNotError(err, "Update Default Status")
+
+	_, err = c.ProcessingApplication(&equifax.ProcessingApplication{ApplicationID: "1"})
+	u.AssertNotError(err, "Processing Application")
+
+	app, ok := srv.Application("1")
+	if !ok {
+		t.Fatal("application not found")
+	}
+	if app.Status != equifax.ApplicationStatusType1 || app.DefaultStatus != equifax.DefaultStatusType3 || !app.Processed {
+		t.Fatalf("unexpected application state: %+v", app)
+	}
+
+	_, err = c.DeleteApplication(&equifax.DeleteApplication{ApplicationID: "1"})
+	u.AssertNotError(err, "Delete Application")
+
+	_, err = c.OutputVector(&equifax.OutputVector{ApplicationID: "1"})
+	if statusErr, ok := err.(*equifax.StatusError); !ok || statusErr.Status != equifax.StatusType3 {
+		t.Fatalf("expected status 3, got %v", err)
+	}
+}
+
+func TestClientScriptedReplies(t *testing.T) {
+	srv := equifaxtest.NewFraudServer()
+	defer srv.Close()
+
+	srv.Enqueue("newApplication",
+		equifaxtest.Reply{Status: equifax.StatusType1},
+		equifaxtest.Reply{Fault: &equifax.SOAPFault{Code: "soapenv:Server", String: "internal error"}},
+		equifaxtest.Reply{Malformed: true},
 	)
-	resp, err := c.NewApplication(&equifax.NewApplication{})
-	t.Log(resp, err.(*equifax.SOAPFault).Code)
+
+	c := equifax.NewEquifaxFraud(srv.URL, "", "", "", false, 15*time.Second, nil, nil)
+	req := &equifax.NewApplication{ApplicationID: "1", LastName: "Иванов", FirstName: "Иван"}
+
+	_, err := c.NewApplication(req)
+	if !equifax.IsQuarantined(err) {
+		t.Fatalf("expected quarantine, got %v", err)
+	}
+
+	_, err = c.NewApplication(req)
+	if fault, ok := err.(*equifax.SOAPFault); !ok || fault.Code != "soapenv:Server" {
+		t.Fatalf("expected SOAP fault, got %v", err)
+	}
+
+	_, err = c.NewApplication(req)
+	if err == nil {
+		t.Fatal("expected malformed response error")
+	}
+
+	_, err = c.NewApplication(req)
+	if err != nil {
+		t.Fatalf("expected default behaviour after scripted replies, got %v", err)
+	}
+
+	if calls := srv.Calls("newApplication"); calls != 4 {
+		t.Fatalf("expected 4 calls, got %d", calls)
+	}
+
+	client := equifax.NewSOAPClient(srv.URL, false, 15*time.Second, nil, nil)
+	err = client.Call("#unknown", &struct {
+		XMLName xml.Name `xml:"fps:unknown"`
+	}{}, new(equifax.NewApplicationResponse))
+	if fault, ok := err.(*equifax.SOAPFault); !ok || !strings.Contains(fault.String, "unknown") {
+		t.Fatalf("expected SOAP fault for unknown operation, got %v", err)
+	}
+}
+
+func TestClientContextDeadline(t *testing.T) {
+	srv := equifaxtest.NewFraudServer()
+	defer srv.Close()
+
+	srv.Enqueue("outputVector", equifaxtest.Reply{Delay: 5 * time.Second})
+
+	c := equifax.NewEquifaxFraud(srv.URL, "", "", "", false, 15*time.Second, nil, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.OutputVectorContext(ctx, &equifax.OutputVector{ApplicationID: "1"})
+	if err == nil {
+		t.Fatal("expected deadline error")
+	}
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Fatalf("expected context deadline, got %v", ctx.Err())
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Fatalf("call was not cancelled: %s", time.Since(start))
+	}
 }
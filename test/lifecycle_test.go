@@ -0,0 +1,67 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/l-vitaly/equifax"
+	"github.com/l-vitaly/equifax/equifaxtest"
+	"github.com/l-vitaly/gounit"
+)
+
+func TestApplicationLifecycle(t *testing.T) {
+	u := gounit.New(t)
+	ctx := context.Background()
+
+	srv := equifaxtest.NewFraudServer()
+	defer srv.Close()
+
+	l := equifax.NewApplicationLifecycle(equifax.NewEquifaxFraud(srv.URL, "", "", "", false, time.Second, nil, nil))
+
+	_, err := l.NewApplication(ctx, &equifax.NewApplication{
+		ApplicationID:     "1",
+		LastName:          "Иванов",
+		FirstName:         "Иван",
+		ApplicationStatus: equifax.ApplicationStatusType9,
+		DefaultStatus:     equifax.DefaultStatusType9,
+	})
+	u.AssertNotError(err, "New Application")
+
+	_, err = l.UpdateCreditStatus(ctx, &equifax.UpdateCreditStatus{ApplicationID: "1", ApplicationStatus: equifax.ApplicationStatusType8})
+	if statusErr, ok := err.(*equifax.StatusError); !ok || statusErr.Status != equifax.StatusType26 {
+		t.Fatalf("expected status 26, got %v", err)
+	}
+
+	_, err = l.UpdateDefaultStatus(ctx, &equifax.UpdateDefaultStatus{ApplicationID: "1", DefaultStatus: equifax.DefaultStatusType9})
+	if statusErr, ok := err.(*equifax.StatusError); !ok || statusErr.Status != equifax.StatusType25 {
+		t.Fatalf("expected status 25, got %v", err)
+	}
+
+	if calls := srv.Calls("updateCreditStatus") + srv.Calls("updateDefaultStatus"); calls != 0 {
+		t.Fatalf("invalid transitions must not be sent, got %d calls", calls)
+	}
+
+	_, err = l.UpdateCreditStatus(ctx, &equifax.UpdateCreditStatus{ApplicationID: "1", ApplicationStatus: equifax.ApplicationStatusType1})
+	u.AssertNotError(err, "Update Credit Status")
+
+	_, err = l.UpdateFraudStatus(ctx, &equifax.UpdateFraudStatus{ApplicationID: "1", ApplicationFraudStatus: equifax.ApplicationFraudStatusType3})
+	u.AssertNotError(err, "Update Fraud Status")
+
+	_, err = l.UpdateFraudStatus(ctx, &equifax.UpdateFraudStatus{ApplicationID: "1", ApplicationFraudStatus: equifax.ApplicationFraudStatusType3})
+	if !equifax.IsDuplicate(err) {
+		t.Fatalf("expected repeated status error, got %v", err)
+	}
+
+	state, ok := l.State("1")
+	if !ok || state.Status != equifax.ApplicationStatusType1 || state.FraudStatus != equifax.ApplicationFraudStatusType3 || state.DefaultStatus != equifax.DefaultStatusType9 {
+		t.Fatalf("unexpected state: %+v", state)
+	}
+
+	_, err = l.DeleteApplication(ctx, &equifax.DeleteApplication{ApplicationID: "1"})
+	u.AssertNotError(err, "Delete Application")
+
+	if _, ok := l.State("1"); ok {
+		t.Fatal("deleted application is still tracked")
+	}
+}
@@ -0,0 +1,45 @@
+package test
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/l-vitaly/equifax"
+	"github.com/l-vitaly/gounit"
+)
+
+func loadReport(t *testing.T) *equifax.CreditResponse {
+	u := gounit.New(t)
+
+	data, err := ioutil.ReadFile("./report.xml")
+	u.AssertNotError(err, "Read Report")
+
+	var resp *equifax.CreditResponse
+	err = xml.Unmarshal(data, &resp)
+	u.AssertNotError(err, "Unmarshal Report")
+
+	return resp
+}
+
+func TestReportParts(t *testing.T) {
+	resp := loadReport(t)
+
+	individual := resp.Response.TitlePart.Individual
+	if individual == nil || individual.LastName != "СЕРГЕЕВ" || len(individual.Documents) != 1 {
+		t.Fatalf("unexpected title part: %+v", individual)
+	}
+	if !individual.Birthday.Equal(time.Date(1975, 1, 20, 0, 0, 0, 0, equifax.BureauLocation)) {
+		t.Fatalf("unexpected birthday: %s", individual.Birthday)
+	}
+
+	addresses := resp.Response.BasePart.AddressReg
+	if len(addresses) != 1 || addresses[0].City != "МОСКВА" || addresses[0].House != "17" {
+		t.Fatalf("unexpected addresses: %+v", addresses)
+	}
+
+	if len(resp.Response.BasePart.Data) == 0 {
+		t.Fatal("raw base part is not kept")
+	}
+}
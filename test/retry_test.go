@@ -0,0 +1,130 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/l-vitaly/equifax"
+	"github.com/l-vitaly/gounit"
+)
+
+const soapResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/">
+<soapenv:Body><%[1]s><applicationid>1</applicationid><status>%[2]d</status></%[1]s></soapenv:Body>
+</soapenv:Envelope>`
+
+func retryServer(calls *int32, fail int32, handler func(w http.ResponseWriter)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		if atomic.AddInt32(calls, 1) <= fail {
+			handler(w)
+			return
+		}
+		fmt.Fprintf(w, soapResponse, "outputVectorResponse", 0)
+	}))
+}
+
+func testBackoff() *equifax.Backoff {
+	b := equifax.NewBackoff(3)
+	b.Initial = time.Millisecond
+	return b
+}
+
+func TestRetryIdempotentServerError(t *testing.T) {
+	u := gounit.New(t)
+
+	var calls int32
+	srv := retryServer(&calls, 2, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer srv.Close()
+
+	c := equifax.NewEquifaxFraud(srv.URL, "", "", "", false, time.Second, nil, nil)
+	c.SetRetryPolicy(testBackoff())
+
+	_, err := c.OutputVector(&equifax.OutputVector{ApplicationID: "1"})
+	u.AssertNotError(err, "Output Vector")
+
+	if calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls)
+	}
+}
+
+func TestRetryNewApplicationServerError(t *testing.T) {
+	var calls int32
+	srv := retryServer(&calls, 2, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	defer srv.Close()
+
+	c := equifax.NewEquifaxFraud(srv.URL, "", "", "", false, time.Second, nil, nil)
+	c.SetRetryPolicy(testBackoff())
+
+	_, err := c.NewApplicationContext(context.Background(), &equifax.NewApplication{ApplicationID: "1"})
+	if _, ok := err.(*equifax.HTTPError); !ok {
+		t.Fatalf("expected HTTPError, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("newApplication must not be retried after 5xx, got %d attempts", calls)
+	}
+}
+
+func TestRetryStatus98(t *testing.T) {
+	var calls int32
+	srv := retryServer(&calls, 3, func(w http.ResponseWriter) {
+		fmt.Fprintf(w, soapResponse, "outputVectorResponse", equifax.StatusType98)
+	})
+	defer srv.Close()
+
+	c := equifax.NewEquifaxFraud(srv.URL, "", "", "", false, time.Second, nil, nil)
+	c.SetRetryPolicy(testBackoff())
+
+	_, err := c.OutputVector(&equifax.OutputVector{ApplicationID: "1"})
+	statusErr, ok := err.(*equifax.StatusError)
+	if !ok || statusErr.Status != equifax.StatusType98 || !equifax.IsRetryable(err) {
+		t.Fatalf("expected status 98 error, got %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls)
+	}
+}
+
+func TestHTTPStatusWithoutFault(t *testing.T) {
+	for _, tc := range []struct {
+		code int
+		body string
+	}{
+		{http.StatusUnauthorized, ""},
+		{http.StatusForbidden, "<html><body>Forbidden</body></html>"},
+		{http.StatusNotFound, "not found"},
+		{http.StatusNoContent, ""},
+		{http.StatusOK, ""},
+	} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ioutil.ReadAll(r.Body)
+			w.WriteHeader(tc.code)
+			fmt.Fprint(w, tc.body)
+		}))
+
+		c := equifax.NewEquifaxFraud(srv.URL, "", "", "", false, time.Second, nil, nil)
+		resp, err := c.OutputVector(&equifax.OutputVector{ApplicationID: "1"})
+		srv.Close()
+
+		if resp != nil {
+			t.Fatalf("status %d: unexpected response %+v", tc.code, resp)
+		}
+		httpErr, ok := err.(*equifax.HTTPError)
+		switch {
+		case tc.code/100 != 2 && (!ok || httpErr.StatusCode != tc.code):
+			t.Fatalf("status %d: expected HTTPError, got %v", tc.code, err)
+		case tc.code/100 == 2 && err != equifax.ErrEmptyResponse:
+			t.Fatalf("status %d: expected ErrEmptyResponse, got %v", tc.code, err)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !nolibxml2
+// +build !nolibxml2
+
+package test
+
+// testSchema - XSD-схема запросов для тестового бюро и клиента.
+const testSchema = "./schema.xml"
@@ -0,0 +1,7 @@
+//go:build nolibxml2
+// +build nolibxml2
+
+package test
+
+// testSchema пустая: в сборке без libxml2 проверка по XSD-схеме недоступна.
+const testSchema = ""
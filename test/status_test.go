@@ -0,0 +1,81 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/l-vitaly/equifax"
+	"github.com/l-vitaly/gounit"
+)
+
+func statusServer(action string, status equifax.Status) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		fmt.Fprintf(w, soapResponse, action, status)
+	}))
+}
+
+func TestStatusError(t *testing.T) {
+	srv := statusServer("newApplicationResponse", equifax.StatusType15)
+	defer srv.Close()
+
+	c := equifax.NewEquifaxFraud(srv.URL, "", "", "", false, time.Second, nil, nil)
+
+	resp, err := c.NewApplication(&equifax.NewApplication{ApplicationID: "1"})
+	if resp != nil {
+		t.Fatal("expected nil response")
+	}
+	if !equifax.IsDuplicate(err) {
+		t.Fatalf("expected duplicate error, got %v", err)
+	}
+	if equifax.IsQuarantined(err) || equifax.IsPhotoError(err) || equifax.IsRetryable(err) {
+		t.Fatalf("unexpected predicate match for %v", err)
+	}
+
+	statusErr := err.(*equifax.StatusError)
+	if statusErr.MessageRU() != "кредитная заявка с данным ID уже есть в Системе" {
+		t.Fatalf("unexpected message: %s", statusErr.MessageRU())
+	}
+}
+
+func TestStatusWarning(t *testing.T) {
+	u := gounit.New(t)
+
+	srv := statusServer("outputVectorResponse", equifax.StatusType47)
+	defer srv.Close()
+
+	c := equifax.NewEquifaxFraud(srv.URL, "", "", "", false, time.Second, nil, nil)
+
+	resp, err := c.OutputVector(&equifax.OutputVector{ApplicationID: "1"})
+	u.AssertNotError(err, "Output Vector")
+
+	if resp.Warning == nil || resp.Warning.Status != equifax.StatusType47 {
+		t.Fatalf("expected status 47 warning, got %v", resp.Warning)
+	}
+}
+
+func TestResponseCodeError(t *testing.T) {
+	var err error = &equifax.ResponseCodeError{Code: equifax.ResponseCodeType30, Text: "нет согласия"}
+
+	if !errors.Is(err, equifax.ErrConsentMissing) {
+		t.Fatalf("expected ErrConsentMissing, got %v", err)
+	}
+	if errors.Is(err, equifax.ErrServiceUnavailable) {
+		t.Fatal("unexpected ErrServiceUnavailable")
+	}
+
+	var codeErr *equifax.ResponseCodeError
+	if !errors.As(err, &codeErr) || codeErr.Code != equifax.ResponseCodeType30 {
+		t.Fatalf("expected response code 30, got %v", err)
+	}
+
+	err = &equifax.ResponseCodeError{Code: 77}
+	if !errors.Is(err, equifax.ErrUnknownResponseCode) {
+		t.Fatalf("expected ErrUnknownResponseCode, got %v", err)
+	}
+}
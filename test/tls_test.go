@@ -0,0 +1,102 @@
+package test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/l-vitaly/equifax"
+	"github.com/l-vitaly/equifax/equifaxtest"
+	"github.com/l-vitaly/gounit"
+)
+
+func createTestApplication(c equifax.EquifaxFraud) error {
+	_, err := c.NewApplication(&equifax.NewApplication{ApplicationID: "1", LastName: "Иванов", FirstName: "Иван"})
+	return err
+}
+
+func TestTLSVerification(t *testing.T) {
+	u := gounit.New(t)
+
+	srv := equifaxtest.NewTLSFraudServer(nil)
+	defer srv.Close()
+
+	c := equifax.NewEquifaxFraud(srv.URL, "login", "password", "partner", true, 15*time.Second, nil, nil)
+	var uerr x509.UnknownAuthorityError
+	if err := createTestApplication(c); !errors.As(err, &uerr) {
+		t.Fatalf("expected unknown authority error, got %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	c, err := equifax.NewEquifaxFraudTLS(srv.URL, "login", "password", "partner",
+		&equifax.TLSConfig{RootCAs: roots, ServerName: "example.com"}, 15*time.Second, nil, nil)
+	u.AssertNotError(err, "New Client")
+	u.AssertNotError(createTestApplication(c), "New Application")
+
+	c, err = equifax.NewEquifaxFraudTLS(srv.URL, "login", "password", "partner",
+		&equifax.TLSConfig{RootCAs: roots, ServerName: "equifax.test"}, 15*time.Second, nil, nil)
+	u.AssertNotError(err, "New Client")
+	var herr x509.HostnameError
+	if err := createTestApplication(c); !errors.As(err, &herr) {
+		t.Fatalf("expected hostname error, got %v", err)
+	}
+
+	c, err = equifax.NewEquifaxFraudTLS(srv.URL, "login", "password", "partner",
+		&equifax.TLSConfig{InsecureSkipVerify: true}, 15*time.Second, nil, nil)
+	u.AssertNotError(err, "New Client")
+	if err := createTestApplication(c); !equifax.IsDuplicate(err) {
+		t.Fatalf("expected duplicate error, got %v", err)
+	}
+}
+
+func TestTLSClientCertificate(t *testing.T) {
+	u := gounit.New(t)
+
+	partner, err := equifaxtest.NewSigner("partner")
+	u.AssertNotError(err, "New Signer")
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(partner.Certificate)
+	srv := equifaxtest.NewTLSFraudServer(&tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs})
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "equifax-tls")
+	u.AssertNotError(err, "Temp Dir")
+	defer os.RemoveAll(dir)
+
+	key, err := x509.MarshalPKCS8PrivateKey(partner.Key)
+	u.AssertNotError(err, "Marshal Key")
+	files := map[string]*pem.Block{
+		"ca.pem":   {Type: "CERTIFICATE", Bytes: srv.Certificate().Raw},
+		"cert.pem": {Type: "CERTIFICATE", Bytes: partner.Certificate.Raw},
+		"key.pem":  {Type: "PRIVATE KEY", Bytes: key},
+	}
+	for name, block := range files {
+		u.AssertNotError(ioutil.WriteFile(filepath.Join(dir, name), pem.EncodeToMemory(block), 0600), "Write "+name)
+	}
+
+	config := &equifax.TLSConfig{CAFile: filepath.Join(dir, "ca.pem"), MinVersion: tls.VersionTLS13}
+	c, err := equifax.NewEquifaxFraudTLS(srv.URL, "login", "password", "partner", config, 15*time.Second, nil, nil)
+	u.AssertNotError(err, "New Client")
+	if err := createTestApplication(c); err == nil {
+		t.Fatal("expected handshake error without client certificate")
+	}
+
+	config.CertFile = filepath.Join(dir, "cert.pem")
+	config.KeyFile = filepath.Join(dir, "key.pem")
+	c, err = equifax.NewEquifaxFraudTLS(srv.URL, "login", "password", "partner", config, 15*time.Second, nil, nil)
+	u.AssertNotError(err, "New Client")
+	u.AssertNotError(createTestApplication(c), "New Application")
+
+	_, err = equifax.NewSOAPClientTLS(srv.URL, &equifax.TLSConfig{CAFile: config.KeyFile}, 15*time.Second, nil, nil)
+	if err == nil {
+		t.Fatal("expected error for ca file without certificates")
+	}
+}
@@ -0,0 +1,103 @@
+package test
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http/httptrace"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/l-vitaly/equifax"
+	"github.com/l-vitaly/equifax/equifaxtest"
+	"github.com/l-vitaly/gounit"
+)
+
+func TestTransportKeepAlive(t *testing.T) {
+	u := gounit.New(t)
+
+	srv := equifaxtest.NewFraudServer()
+	defer srv.Close()
+
+	c, err := equifax.NewEquifaxFraudConfig(srv.URL, "login", "password", "partner",
+		&equifax.TransportConfig{Timeout: 15 * time.Second, ResponseHeaderTimeout: 5 * time.Second}, nil, nil)
+	u.AssertNotError(err, "New Client")
+	u.AssertNotError(createTestApplication(c), "New Application")
+
+	var reused int
+	ctx := httptrace.WithClientTrace(context.Background(), &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				reused++
+			}
+		},
+	})
+	for i := 0; i < 3; i++ {
+		_, err = c.OutputVectorContext(ctx, &equifax.OutputVector{ApplicationID: "1"})
+		u.AssertNotError(err, "Output Vector")
+	}
+	if reused != 3 {
+		t.Fatalf("expected 3 reused connections, got %d", reused)
+	}
+}
+
+func TestTransportProxy(t *testing.T) {
+	u := gounit.New(t)
+
+	proxy := equifaxtest.NewFraudServer()
+	defer proxy.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	u.AssertNotError(err, "Parse Proxy URL")
+
+	c, err := equifax.NewEquifaxFraudConfig("http://fps.equifax.test/FPSPartner", "login", "password", "partner",
+		&equifax.TransportConfig{ProxyURL: proxyURL, Timeout: 15 * time.Second}, nil, nil)
+	u.AssertNotError(err, "New Client")
+	u.AssertNotError(createTestApplication(c), "New Application")
+
+	if calls := proxy.Calls("newApplication"); calls != 1 {
+		t.Fatalf("expected request through proxy, got %d calls", calls)
+	}
+}
+
+// BenchmarkTransport сравнивает вызовы FPS по HTTPS через пул соединений и с новым
+// соединением на каждый вызов.
+func BenchmarkTransport(b *testing.B) {
+	srv := equifaxtest.NewTLSFraudServer(nil)
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+
+	for _, bc := range []struct {
+		name      string
+		keepAlive bool
+	}{
+		{"KeepAlive", true},
+		{"NewConnection", false},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			c, err := equifax.NewEquifaxFraudConfig(srv.URL, "login", "password", "partner", &equifax.TransportConfig{
+				TLS:               &equifax.TLSConfig{RootCAs: roots, ServerName: "example.com"},
+				DisableKeepAlives: !bc.keepAlive,
+				Timeout:           15 * time.Second,
+			}, nil, nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err = createTestApplication(c); err != nil && !equifax.IsDuplicate(err) {
+				b.Fatal(err)
+			}
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := c.OutputVector(&equifax.OutputVector{ApplicationID: "1"}); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/l-vitaly/equifax"
+	"github.com/l-vitaly/gounit"
+)
+
+type typesDoc struct {
+	XMLName xml.Name            `xml:"doc" json:"-"`
+	Date    equifax.Date        `xml:"date,attr" json:"date"`
+	Time    equifax.Time        `xml:"time" json:"time"`
+	Empty   equifax.EmptyString `xml:"empty" json:"empty"`
+	Null    equifax.EmptyString `xml:"null" json:"null"`
+	Zero    equifax.Date        `xml:"zero" json:"zero"`
+}
+
+func TestTypesRoundTrip(t *testing.T) {
+	u := gounit.New(t)
+
+	doc := &typesDoc{
+		Date: equifax.Date{time.Date(2017, 5, 20, 0, 0, 0, 0, equifax.BureauLocation)},
+		Time: equifax.Time{time.Date(2017, 5, 20, 10, 30, 15, 0, equifax.BureauLocation)},
+		Null: equifax.Null,
+	}
+
+	data, err := xml.Marshal(doc)
+	u.AssertNotError(err, "Marshal XML")
+	if string(data) != `<doc date="20.05.2017"><time>20.05.2017 10:30:15</time><empty>EMPTY</empty><null>NULL</null></doc>` {
+		t.Fatalf("unexpected xml: %s", data)
+	}
+
+	decoded := new(typesDoc)
+	u.AssertNotError(xml.Unmarshal(data, decoded), "Unmarshal XML")
+	assertTypesDoc(t, decoded, doc)
+
+	data, err = json.Marshal(doc)
+	u.AssertNotError(err, "Marshal JSON")
+	if string(data) != `{"date":"20.05.2017","time":"20.05.2017 10:30:15","empty":"","null":"NULL","zero":null}` {
+		t.Fatalf("unexpected json: %s", data)
+	}
+
+	decoded = new(typesDoc)
+	u.AssertNotError(json.Unmarshal(data, decoded), "Unmarshal JSON")
+	assertTypesDoc(t, decoded, doc)
+
+	var zero equifax.Date
+	value, err := zero.MarshalCSV()
+	u.AssertNotError(err, "Marshal CSV")
+	if value != "01.01.1900" {
+		t.Fatalf("expected 01.01.1900 for zero date in csv, got %s", value)
+	}
+
+	data, err = xml.Marshal(&struct {
+		XMLName xml.Name     `xml:"doc"`
+		Date    equifax.Date `xml:"date,attr"`
+		Time    equifax.Time `xml:"time"`
+	}{})
+	u.AssertNotError(err, "Marshal XML")
+	if string(data) != `<doc></doc>` {
+		t.Fatalf("expected zero dates to be omitted, got %s", data)
+	}
+
+	var empty equifax.EmptyString
+	u.AssertNotError(json.Unmarshal([]byte(`"EMPTY"`), &empty), "Unmarshal EMPTY")
+	if empty != "" {
+		t.Fatalf("expected empty string, got %q", empty)
+	}
+}
+
+func TestTypesSQL(t *testing.T) {
+	u := gounit.New(t)
+
+	value, err := equifax.EmptyString("").Value()
+	u.AssertNotError(err, "EmptyString Value")
+	if value != nil {
+		t.Fatalf("expected NULL for empty string, got %v", value)
+	}
+
+	var s equifax.EmptyString
+	u.AssertNotError(s.Scan([]byte("EMPTY")), "EmptyString Scan")
+	if s != "" {
+		t.Fatalf("expected empty string, got %q", s)
+	}
+
+	value, err = equifax.Date{}.Value()
+	u.AssertNotError(err, "Date Value")
+	if value != nil {
+		t.Fatalf("expected NULL for zero date, got %v", value)
+	}
+
+	now := time.Now()
+	value, err = equifax.Time{now}.Value()
+	u.AssertNotError(err, "Time Value")
+
+	var tm equifax.Time
+	u.AssertNotError(tm.Scan(value), "Time Scan")
+	if !tm.Equal(now) {
+		t.Fatalf("expected %s, got %s", now, tm)
+	}
+
+	var d equifax.Date
+	u.AssertNotError(d.Scan("20.05.2017"), "Date Scan")
+	if d.Year() != 2017 || d.Month() != time.May || d.Day() != 20 {
+		t.Fatalf("unexpected date %s", d)
+	}
+	u.AssertNotError(d.Scan(nil), "Date Scan NULL")
+	if !d.IsZero() {
+		t.Fatalf("expected zero date, got %s", d)
+	}
+}
+
+func assertTypesDoc(t *testing.T, got, want *typesDoc) {
+	t.Helper()
+
+	if !got.Date.Equal(want.Date.Time) || !got.Time.Equal(want.Time.Time) || !got.Zero.IsZero() {
+		t.Fatalf("unexpected dates: %+v", got)
+	}
+	if got.Empty != want.Empty || got.Null != want.Null {
+		t.Fatalf("unexpected strings: %q, %q", got.Empty, got.Null)
+	}
+}
+
+func TestTypesBureauLocation(t *testing.T) {
+	u := gounit.New(t)
+
+	vladivostok := time.FixedZone("VLAT", 10*60*60)
+
+	data, err := xml.Marshal(&struct {
+		XMLName xml.Name     `xml:"doc"`
+		Time    equifax.Time `xml:"time"`
+		Date    equifax.Date `xml:"date"`
+	}{
+		Time: equifax.Time{time.Date(2017, 5, 20, 2, 0, 0, 0, vladivostok)},
+		Date: equifax.Date{time.Date(2017, 5, 20, 0, 0, 0, 0, vladivostok)},
+	})
+	u.AssertNotError(err, "Marshal XML")
+	if string(data) != `<doc><time>19.05.2017 19:00:00</time><date>20.05.2017</date></doc>` {
+		t.Fatalf("unexpected xml: %s", data)
+	}
+
+	var tm equifax.Time
+	u.AssertNotError(tm.UnmarshalCSV("20.05.2017 10:00:00"), "Time Unmarshal")
+	if !tm.Equal(time.Date(2017, 5, 20, 7, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected Moscow time, got %s", tm)
+	}
+
+	u.AssertNotError(tm.UnmarshalCSV("01.01.1900 00:00:00"), "Time Unmarshal")
+	if !tm.IsZero() {
+		t.Fatalf("expected zero time, got %s", tm)
+	}
+}
@@ -0,0 +1,99 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/l-vitaly/equifax"
+	"github.com/l-vitaly/gounit"
+)
+
+func TestValidateNewApplication(t *testing.T) {
+	u := gounit.New(t)
+
+	app := &equifax.NewApplication{
+		ApplicationID:   "1",
+		ApplicationDate: equifax.Time{time.Date(2017, 5, 20, 10, 0, 0, 0, equifax.BureauLocation)},
+		LastName:        "Иванов",
+		FirstName:       "Иван",
+		Birthday:        equifax.Date{time.Date(1980, 1, 1, 0, 0, 0, 0, equifax.BureauLocation)},
+		DocType:         equifax.DocType1,
+		DocDate:         equifax.Date{time.Date(2005, 1, 1, 0, 0, 0, 0, equifax.BureauLocation)},
+		Citizenship:     equifax.CountryTypeRU,
+		INN:             "500100732259",
+		PFR:             "11223344595",
+		EmploymentINN:   "7707083893",
+		MobilePhone:     "9161002030",
+		LaIndex:         "354340",
+		Sex:             equifax.SexType1,
+	}
+	u.AssertNotError(app.Validate(), "Validate")
+
+	app.FirstName = ""
+	app.INN = "500100732258"
+	app.PFR = "112233445"
+	app.MobilePhone = "+79161002030"
+	app.LaIndex = "35434"
+	app.DocType = 42
+	app.Citizenship = "XX"
+	app.DocDate = equifax.Date{time.Date(2018, 1, 1, 0, 0, 0, 0, equifax.BureauLocation)}
+	app.DefaultStatus = equifax.DefaultStatusType8
+
+	err := app.Validate()
+	errs, ok := err.(equifax.ValidationError)
+	if !ok {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+
+	fields := make(map[string]bool)
+	for _, e := range errs {
+		fields[e.Field] = true
+	}
+	for _, field := range []string{"firstname", "inn", "pfr", "mobilephone", "la_index", "doctype", "citizenship", "docdate", "defaultstatus"} {
+		if !fields[field] {
+			t.Errorf("expected error for %s in %v", field, err)
+		}
+	}
+	if len(errs) != 9 {
+		t.Errorf("expected 9 field errors, got %v", err)
+	}
+	if errs.Status() != equifax.StatusType2 {
+		t.Errorf("expected status 2, got %d", errs.Status())
+	}
+}
+
+func TestValidateNull(t *testing.T) {
+	app := &equifax.NewApplication{
+		ApplicationID:   "1",
+		ApplicationDate: equifax.Time{time.Date(2017, 5, 20, 10, 0, 0, 0, equifax.BureauLocation)},
+		LastName:        "Иванов",
+		FirstName:       "Иван",
+		Birthday:        equifax.Date{time.Date(1980, 1, 1, 0, 0, 0, 0, equifax.BureauLocation)},
+		Citizenship:     equifax.CountryTypeRU,
+		INN:             equifax.Null,
+		PFR:             equifax.Null,
+		EmploymentINN:   equifax.Null,
+		DocCode:         equifax.Null,
+		HomePhone:       equifax.Null,
+		MobilePhone:     equifax.Null,
+		LaIndex:         equifax.Null,
+		RaIndex:         equifax.Null,
+	}
+	gounit.New(t).AssertNotError(app.Validate(), "Validate")
+}
+
+func TestValidateStatusUpdates(t *testing.T) {
+	u := gounit.New(t)
+
+	u.AssertNotError((&equifax.UpdateCreditStatus{ApplicationID: "1", ApplicationStatus: equifax.ApplicationStatusType1}).Validate(), "Update Credit Status")
+
+	err := (&equifax.UpdateFraudStatus{ApplicationID: "1", ApplicationFraudStatus: equifax.ApplicationFraudStatusType8}).Validate()
+	if errs, ok := err.(equifax.ValidationError); !ok || len(errs) != 1 || errs.Status() != equifax.StatusType5 {
+		t.Fatalf("expected status 5 validation error, got %v", err)
+	}
+
+	err = (&equifax.UpdateDefaultStatus{}).Validate()
+	if errs, ok := err.(equifax.ValidationError); !ok || len(errs) != 2 {
+		t.Fatalf("expected 2 field errors, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package equifax
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// TLSConfig - настройки TLS соединения с сервисом Equifax. Сертификат сервера проверяется
+// всегда, отключить проверку можно только явным InsecureSkipVerify.
+type TLSConfig struct {
+	RootCAs      *x509.CertPool    // доверенные корневые сертификаты; по умолчанию системные
+	CAFile       string            // PEM-файл с сертификатами УЦ сервиса; добавляется к RootCAs, без RootCAs заменяет системные
+	Certificates []tls.Certificate // клиентские сертификаты партнера для взаимной аутентификации
+	CertFile     string            // PEM-файл клиентского сертификата партнера
+	KeyFile      string            // PEM-файл закрытого ключа клиентского сертификата
+	MinVersion   uint16            // минимальная версия TLS; по умолчанию TLS 1.2
+	ServerName   string            // имя сервера для проверки сертификата, если отличается от хоста в URL
+
+	// InsecureSkipVerify отключает проверку сертификата сервера. Только для отладки:
+	// соединение без проверки уязвимо для подмены сервиса.
+	InsecureSkipVerify bool
+}
+
+// Config возвращает tls.Config по настройкам: загружает CAFile и пару CertFile/KeyFile.
+// Для nil возвращается конфигурация по умолчанию с системными корневыми сертификатами.
+func (c *TLSConfig) Config() (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c == nil {
+		return config, nil
+	}
+
+	if c.MinVersion != 0 {
+		config.MinVersion = c.MinVersion
+	}
+	config.ServerName = c.ServerName
+	config.InsecureSkipVerify = c.InsecureSkipVerify
+	config.RootCAs = c.RootCAs
+
+	if c.CAFile != "" {
+		pem, err := ioutil.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "equifax: read ca file")
+		}
+		if config.RootCAs == nil {
+			config.RootCAs = x509.NewCertPool()
+		} else {
+			config.RootCAs = config.RootCAs.Clone()
+		}
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("equifax: no certificates in ca file %s", c.CAFile)
+		}
+	}
+
+	config.Certificates = append(config.Certificates, c.Certificates...)
+	if c.CertFile != "" || c.KeyFile != "" {
+		crt, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "equifax: load client certificate")
+		}
+		config.Certificates = append(config.Certificates, crt)
+	}
+	return config, nil
+}
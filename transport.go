@@ -0,0 +1,113 @@
+package equifax
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Значения TransportConfig по умолчанию.
+const (
+	DefaultMaxIdleConns        = 100
+	DefaultMaxIdleConnsPerHost = 16
+	DefaultIdleConnTimeout     = 90 * time.Second
+	DefaultDialTimeout         = 30 * time.Second
+	DefaultKeepAlive           = 30 * time.Second
+	DefaultTLSHandshakeTimeout = 10 * time.Second
+)
+
+// TransportConfig - настройки HTTP-транспорта клиента. Транспорт держит пул keep-alive
+// соединений и переиспользует их между вызовами, поэтому клиент следует создавать один раз
+// и использовать совместно. Нулевые значения заменяются значениями по умолчанию.
+type TransportConfig struct {
+	TLS *TLSConfig // настройки TLS; nil - настройки по умолчанию
+
+	// пул соединений
+	MaxIdleConns        int           // наибольшее число простаивающих соединений
+	MaxIdleConnsPerHost int           // наибольшее число простаивающих соединений с сервисом
+	MaxConnsPerHost     int           // наибольшее число соединений с сервисом; 0 - без ограничения
+	IdleConnTimeout     time.Duration // время, через которое простаивающее соединение закрывается
+	DisableKeepAlives   bool          // новое соединение на каждый запрос
+
+	// ProxyURL - HTTP-прокси для обращения к сервису. Если не задан, прокси берется из
+	// переменных окружения HTTPS_PROXY, HTTP_PROXY и NO_PROXY.
+	ProxyURL *url.URL
+
+	// таймауты этапов запроса
+	Timeout               time.Duration // весь обмен: соединение, отправка запроса и чтение ответа; 0 - без ограничения
+	DialTimeout           time.Duration // установка TCP-соединения
+	KeepAlive             time.Duration // период TCP keep-alive
+	TLSHandshakeTimeout   time.Duration // TLS-рукопожатие
+	ResponseHeaderTimeout time.Duration // ожидание заголовков ответа после отправки запроса; 0 - без ограничения
+}
+
+// Transport возвращает http.Transport по настройкам. Для nil возвращается транспорт с
+// настройками по умолчанию.
+func (c *TransportConfig) Transport() (*http.Transport, error) {
+	if c == nil {
+		c = new(TransportConfig)
+	}
+
+	tlsConfig, err := c.TLS.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if c.ProxyURL != nil {
+		proxy = http.ProxyURL(c.ProxyURL)
+	}
+
+	dialTimeout := c.DialTimeout
+	if dialTimeout == 0 {
+		dialTimeout = DefaultDialTimeout
+		if c.Timeout != 0 && c.Timeout < dialTimeout {
+			dialTimeout = c.Timeout
+		}
+	}
+
+	return &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   dialTimeout,
+			KeepAlive: durationOr(c.KeepAlive, DefaultKeepAlive),
+		}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   durationOr(c.TLSHandshakeTimeout, DefaultTLSHandshakeTimeout),
+		ResponseHeaderTimeout: c.ResponseHeaderTimeout,
+		DisableKeepAlives:     c.DisableKeepAlives,
+		MaxIdleConns:          intOr(c.MaxIdleConns, DefaultMaxIdleConns),
+		MaxIdleConnsPerHost:   intOr(c.MaxIdleConnsPerHost, DefaultMaxIdleConnsPerHost),
+		MaxConnsPerHost:       c.MaxConnsPerHost,
+		IdleConnTimeout:       durationOr(c.IdleConnTimeout, DefaultIdleConnTimeout),
+	}, nil
+}
+
+// Client возвращает http.Client с транспортом Transport и общим таймаутом Timeout.
+func (c *TransportConfig) Client() (*http.Client, error) {
+	tr, err := c.Transport()
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Transport: tr}
+	if c != nil {
+		client.Timeout = c.Timeout
+	}
+	return client, nil
+}
+
+func durationOr(d, def time.Duration) time.Duration {
+	if d == 0 {
+		return def
+	}
+	return d
+}
+
+func intOr(n, def int) int {
+	if n == 0 {
+		return def
+	}
+	return n
+}
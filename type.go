@@ -1,7 +1,11 @@
 package equifax
 
 import (
+    "database/sql/driver"
+    "encoding/json"
     "encoding/xml"
+    "fmt"
+    "strings"
     "time"
 )
 
@@ -11,8 +15,28 @@ const dateEquifaxFormat = "02.01.2006"
 const strEmpty = "EMPTY"
 const Null = "NULL"
 
-var emptyDateDefault = time.Date(1900, 1, 1, 0, 0, 0, 0, time.Local)
+// BureauLocation - часовой пояс бюро, в котором передаются дата и время (по умолчанию
+// Europe/Moscow). Задается до начала работы с клиентами.
+var BureauLocation = loadBureauLocation()
 
+func loadBureauLocation() *time.Location {
+    loc, err := time.LoadLocation("Europe/Moscow")
+    if err != nil {
+        return time.FixedZone("MSK", 3*60*60)
+    }
+    return loc
+}
+
+// emptyDateDefault - дата, которой в файле заявок FPS обозначается отсутствие даты; нулевые
+// Time и Date записываются в CSV этой датой, и при разборе она снова дает нулевое значение.
+func emptyDateDefault() time.Time {
+    return time.Date(1900, 1, 1, 0, 0, 0, 0, BureauLocation)
+}
+
+// EmptyString - строка, пустое значение которой передается как EMPTY. При разборе EMPTY
+// превращается в пустую строку, а NULL сохраняется как есть, поэтому оба значения
+// проходят кодирование и разбор без изменений. В JSON пишется исходная строка, в базу
+// данных пустая строка записывается как NULL.
 type EmptyString string
 
 func (t EmptyString) getValue() string {
@@ -22,56 +46,282 @@ func (t EmptyString) getValue() string {
     return (string)(t)
 }
 
+func (t *EmptyString) setValue(value string) {
+    if value == strEmpty {
+        value = ""
+    }
+    *t = EmptyString(value)
+}
+
 func (t EmptyString) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
     e.EncodeElement(t.getValue(), start)
     return nil
 }
 
+func (t *EmptyString) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+    var value string
+    if err := d.DecodeElement(&value, &start); err != nil {
+        return err
+    }
+    t.setValue(value)
+    return nil
+}
+
+func (t EmptyString) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+    return xml.Attr{Name: name, Value: t.getValue()}, nil
+}
+
+func (t *EmptyString) UnmarshalXMLAttr(attr xml.Attr) error {
+    t.setValue(attr.Value)
+    return nil
+}
+
 func (t EmptyString) MarshalCSV() (string, error) {
     return t.getValue(), nil
 }
 
+func (t *EmptyString) UnmarshalCSV(value string) error {
+    t.setValue(value)
+    return nil
+}
+
+func (t EmptyString) MarshalJSON() ([]byte, error) {
+    return json.Marshal(string(t))
+}
+
+func (t *EmptyString) UnmarshalJSON(data []byte) error {
+    return unmarshalJSONValue(data, func(value string) error {
+        t.setValue(value)
+        return nil
+    })
+}
+
+func (t EmptyString) Value() (driver.Value, error) {
+    if t == "" {
+        return nil, nil
+    }
+    return string(t), nil
+}
+
+func (t *EmptyString) Scan(src interface{}) error {
+    switch v := src.(type) {
+    case nil:
+        *t = ""
+    case string:
+        t.setValue(v)
+    case []byte:
+        t.setValue(string(v))
+    default:
+        return fmt.Errorf("equifax: cannot scan %T into EmptyString", src)
+    }
+    return nil
+}
+
+// Time - дата и время в формате Equifax. Перед форматированием время переводится в
+// BureauLocation. Нулевое время в XML не передается (элемент и атрибут опускаются), в CSV
+// записывается как emptyDateDefault. При разборе пустое значение, NULL и emptyDateDefault
+// дают нулевое время.
 type Time struct {
     time.Time
 }
 
 func (et *Time) getValue() string {
-    return et.Format(timeEquifaxFormat)
+    return et.In(BureauLocation).Format(timeEquifaxFormat)
+}
+
+func (et *Time) setValue(value string) error {
+    value = strings.TrimSpace(value)
+    if value == "" || value == Null {
+        et.Time = time.Time{}
+        return nil
+    }
+
+    t, err := time.ParseInLocation(timeEquifaxFormat, value, BureauLocation)
+    if err != nil {
+        return err
+    }
+    if t.Equal(emptyDateDefault()) {
+        t = time.Time{}
+    }
+    et.Time = t
+    return nil
 }
 
 func (et *Time) MarshalCSV() (string, error) {
+    if et.IsZero() {
+        return emptyDateDefault().Format(timeEquifaxFormat), nil
+    }
     return et.getValue(), nil
 }
 
+func (et *Time) UnmarshalCSV(value string) error {
+    return et.setValue(value)
+}
+
 func (et *Time) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+    if et.IsZero() {
+        return xml.Attr{}, nil
+    }
     return xml.Attr{Name: name, Value: et.getValue()}, nil
 }
 
+func (et *Time) UnmarshalXMLAttr(attr xml.Attr) error {
+    return et.setValue(attr.Value)
+}
+
 func (et *Time) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+    if et.IsZero() {
+        return nil
+    }
     e.EncodeElement(et.getValue(), start)
     return nil
 }
 
+func (et *Time) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+    var value string
+    if err := d.DecodeElement(&value, &start); err != nil {
+        return err
+    }
+    return et.setValue(value)
+}
+
+func (et Time) MarshalJSON() ([]byte, error) {
+    if et.IsZero() {
+        return []byte("null"), nil
+    }
+    return json.Marshal(et.getValue())
+}
+
+func (et *Time) UnmarshalJSON(data []byte) error {
+    return unmarshalJSONValue(data, et.setValue)
+}
+
+func (et Time) Value() (driver.Value, error) {
+    if et.IsZero() {
+        return nil, nil
+    }
+    return et.Time, nil
+}
+
+func (et *Time) Scan(src interface{}) error {
+    return scanTime(src, &et.Time, et.setValue)
+}
+
+// Date - дата в формате Equifax. Передается календарная дата значения без перевода в
+// BureauLocation, чтобы полночь в любом часовом поясе не сдвигала день. Нулевая дата в XML
+// не передается (элемент и атрибут опускаются), в CSV записывается как emptyDateDefault.
+// При разборе пустое значение, NULL и emptyDateDefault дают нулевую дату, остальные даты -
+// полночь в BureauLocation.
 type Date struct {
     time.Time
 }
 
 func (et *Date) getValue() string {
-    if et.IsZero() {
-        return ""
-    }
     return et.Format(dateEquifaxFormat)
 }
 
 func (et *Date) MarshalCSV() (string, error) {
+    if et.IsZero() {
+        return emptyDateDefault().Format(dateEquifaxFormat), nil
+    }
     return et.getValue(), nil
 }
 
+func (et *Date) UnmarshalCSV(value string) error {
+    return et.setValue(value)
+}
+
 func (et *Date) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+    if et.IsZero() {
+        return xml.Attr{}, nil
+    }
     return xml.Attr{Name: name, Value: et.getValue()}, nil
 }
 
 func (et *Date) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+    if et.IsZero() {
+        return nil
+    }
     e.EncodeElement(et.getValue(), start)
     return nil
 }
+
+func (et *Date) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+    var value string
+    if err := d.DecodeElement(&value, &start); err != nil {
+        return err
+    }
+    return et.setValue(value)
+}
+
+func (et *Date) UnmarshalXMLAttr(attr xml.Attr) error {
+    return et.setValue(attr.Value)
+}
+
+func (et Date) MarshalJSON() ([]byte, error) {
+    if et.IsZero() {
+        return []byte("null"), nil
+    }
+    return json.Marshal(et.getValue())
+}
+
+func (et *Date) UnmarshalJSON(data []byte) error {
+    return unmarshalJSONValue(data, et.setValue)
+}
+
+func (et Date) Value() (driver.Value, error) {
+    if et.IsZero() {
+        return nil, nil
+    }
+    return et.Time, nil
+}
+
+func (et *Date) Scan(src interface{}) error {
+    return scanTime(src, &et.Time, et.setValue)
+}
+
+func (et *Date) setValue(value string) error {
+    value = strings.TrimSpace(value)
+    if value == "" || value == Null {
+        et.Time = time.Time{}
+        return nil
+    }
+
+    t, err := time.ParseInLocation(dateEquifaxFormat, value, BureauLocation)
+    if err != nil {
+        return err
+    }
+    if t.Equal(emptyDateDefault()) {
+        t = time.Time{}
+    }
+    et.Time = t
+    return nil
+}
+
+// unmarshalJSONValue разбирает строку JSON; null разбирается как пустое значение.
+func unmarshalJSONValue(data []byte, setValue func(string) error) error {
+    var value *string
+    if err := json.Unmarshal(data, &value); err != nil {
+        return err
+    }
+    if value == nil {
+        return setValue("")
+    }
+    return setValue(*value)
+}
+
+func scanTime(src interface{}, dst *time.Time, setValue func(string) error) error {
+    switch v := src.(type) {
+    case nil:
+        *dst = time.Time{}
+    case time.Time:
+        *dst = v
+    case string:
+        return setValue(v)
+    case []byte:
+        return setValue(string(v))
+    default:
+        return fmt.Errorf("equifax: cannot scan %T into time", src)
+    }
+    return nil
+}
@@ -0,0 +1,381 @@
+package equifax
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// FieldError - ошибка значения поля запроса FPS. Status - код, которым FPS отклонил бы
+// запрос: StatusType2 для незаполненного обязательного поля, StatusType5 для неверного формата.
+type FieldError struct {
+	Field  string // имя поля в запросе FPS
+	Status Status
+	Reason string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Reason)
+}
+
+// ValidationError перечисляет все ошибки полей запроса.
+type ValidationError []*FieldError
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return "equifax: invalid request: " + strings.Join(msgs, "; ")
+}
+
+// Status возвращает StatusType2, если не заполнено хотя бы одно обязательное поле, иначе StatusType5.
+func (e ValidationError) Status() Status {
+	for _, err := range e {
+		if err.Status == StatusType2 {
+			return StatusType2
+		}
+	}
+	return StatusType5
+}
+
+const (
+	maxIDLength   = 50  // applicationid, applicantid, photoid
+	maxNameLength = 50  // фамилия, имя, отчество
+	maxTextLength = 255 // остальные текстовые поля
+)
+
+type validator struct {
+	errs ValidationError
+}
+
+func (v *validator) add(field string, status Status, format string, args ...interface{}) {
+	v.errs = append(v.errs, &FieldError{Field: field, Status: status, Reason: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+func (v *validator) required(field string, value string, max int) {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, StatusType2, "is required")
+		return
+	}
+	v.length(field, value, max)
+}
+
+func (v *validator) requiredTime(field string, value Time) {
+	if value.IsZero() {
+		v.add(field, StatusType2, "is required")
+	}
+}
+
+func (v *validator) length(field string, value string, max int) {
+	if n := utf8.RuneCountInString(value); n > max {
+		v.add(field, StatusType5, "length %d exceeds %d", n, max)
+	}
+}
+
+// absent сообщает, что значение не передано: пустая строка или явный Null. Формат
+// и контрольная сумма такого значения не проверяются.
+func absent(value EmptyString) bool {
+	return value == "" || value == Null
+}
+
+// digits проверяет, что переданное значение состоит из n цифр.
+func (v *validator) digits(field string, value EmptyString, n ...int) {
+	if absent(value) {
+		return
+	}
+	if !isDigits(string(value)) {
+		v.add(field, StatusType5, "must contain only digits")
+		return
+	}
+	if len(n) == 0 {
+		return
+	}
+	for _, l := range n {
+		if len(value) == l {
+			return
+		}
+	}
+	v.add(field, StatusType5, "must contain %s digits", joinInts(n))
+}
+
+func (v *validator) phone(field string, value EmptyString) {
+	v.digits(field, value, 10)
+}
+
+func (v *validator) index(field string, value EmptyString) {
+	v.digits(field, value, 6)
+}
+
+func (v *validator) inn(field string, value EmptyString, n ...int) {
+	errs := len(v.errs)
+	v.digits(field, value, n...)
+	if !absent(value) && len(v.errs) == errs && !validINN(string(value)) {
+		v.add(field, StatusType5, "invalid checksum")
+	}
+}
+
+func (v *validator) snils(field string, value EmptyString) {
+	errs := len(v.errs)
+	v.digits(field, value, 11)
+	if !absent(value) && len(v.errs) == errs && !validSNILS(string(value)) {
+		v.add(field, StatusType5, "invalid checksum")
+	}
+}
+
+type enum interface {
+	IsValid() bool
+}
+
+// enum проверяет значение справочника; нулевое значение необязательного поля допускается.
+func (v *validator) enum(field string, value enum, optional bool) {
+	if optional && reflect.ValueOf(value).IsZero() {
+		return
+	}
+	if !value.IsValid() {
+		v.add(field, StatusType5, "unknown value %v", value)
+	}
+}
+
+// partnerStatus проверяет статус, который партнер вправе передать: «Закрыт автоматически»
+// (closed) проставляет только FPS.
+func (v *validator) partnerStatus(field string, value enum, closed bool) {
+	if closed {
+		v.add(field, StatusType5, "value %v is set by FPS only", value)
+		return
+	}
+	v.enum(field, value, false)
+}
+
+// before проверяет, что дата first не позже second; нулевые даты не сравниваются.
+func (v *validator) before(field string, first time.Time, secondField string, second time.Time) {
+	if first.IsZero() || second.IsZero() {
+		return
+	}
+	if first.After(second) {
+		v.add(field, StatusType5, "must not be after %s", secondField)
+	}
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func joinInts(n []int) string {
+	s := make([]string, len(n))
+	for i, v := range n {
+		s[i] = fmt.Sprint(v)
+	}
+	return strings.Join(s, " or ")
+}
+
+// validINN проверяет контрольные цифры ИНН из 10 или 12 цифр.
+func validINN(inn string) bool {
+	checksum := func(weights []int) int {
+		sum := 0
+		for i, w := range weights {
+			sum += w * int(inn[i]-'0')
+		}
+		return sum % 11 % 10
+	}
+
+	switch len(inn) {
+	case 10:
+		return checksum([]int{2, 4, 10, 3, 5, 9, 4, 6, 8}) == int(inn[9]-'0')
+	case 12:
+		return checksum([]int{7, 2, 4, 10, 3, 5, 9, 4, 6, 8}) == int(inn[10]-'0') &&
+			checksum([]int{3, 7, 2, 4, 10, 3, 5, 9, 4, 6, 8}) == int(inn[11]-'0')
+	}
+	return false
+}
+
+// validOGRN проверяет контрольную цифру ОГРН из 13 цифр: остаток от деления первых 12 цифр
+// на 11, взятый по модулю 10.
+func validOGRN(ogrn string) bool {
+	if len(ogrn) != 13 {
+		return false
+	}
+	n, err := strconv.ParseUint(ogrn[:12], 10, 64)
+	if err != nil {
+		return false
+	}
+	return int(n%11%10) == int(ogrn[12]-'0')
+}
+
+// validSNILS проверяет контрольное число СНИЛС из 11 цифр. Номера до 001-001-998
+// контрольным числом не проверяются.
+func validSNILS(snils string) bool {
+	if snils[:9] <= "001001998" {
+		return true
+	}
+
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(snils[i]-'0') * (9 - i)
+	}
+	switch {
+	case sum > 101:
+		sum %= 101
+		if sum == 100 {
+			sum = 0
+		}
+	case sum == 100 || sum == 101:
+		sum = 0
+	}
+	return fmt.Sprintf("%02d", sum) == snils[9:]
+}
+
+// Validate проверяет заявку до отправки: обязательные поля, длины, форматы телефонов,
+// индексов, ИНН и СНИЛС, порядок дат и значения справочников. Возвращает ValidationError
+// со всеми найденными ошибками.
+func (r *NewApplication) Validate() error {
+	v := new(validator)
+
+	v.required("applicationid", r.ApplicationID, maxIDLength)
+	v.requiredTime("applicationdate", r.ApplicationDate)
+	v.length("photoid", r.PhotoID, maxIDLength)
+	v.required("lastname", r.LastName, maxNameLength)
+	v.required("firstname", r.FirstName, maxNameLength)
+	v.length("middlename", string(r.MiddleName), maxNameLength)
+	v.length("pastlastname", string(r.PastLastName), maxNameLength)
+	v.length("birthplace", string(r.Birthplace), maxTextLength)
+	v.length("docno", r.DocNo, maxTextLength)
+	v.length("docplace", string(r.DocPlace), maxTextLength)
+	v.length("employername", string(r.EmployerName), maxTextLength)
+	v.length("applicationfraudstatusdescr", string(r.ApplicationFraudStatusDesc), maxTextLength)
+	v.length("applicantid", string(r.ApplicantID), maxIDLength)
+
+	v.inn("inn", r.INN, 12)
+	v.snils("pfr", r.PFR)
+	v.inn("employment_inn", r.EmploymentINN, 10, 12)
+	v.digits("doccode", r.DocCode, 6)
+
+	v.phone("homephone", r.HomePhone)
+	v.phone("mobilephone", r.MobilePhone)
+	v.phone("ra_phone", r.RaPhone)
+	v.phone("ba_phone", r.BaPhone)
+	v.phone("pos_phone", r.PosPhone)
+
+	v.index("la_index", r.LaIndex)
+	v.index("ra_index", r.RaIndex)
+	v.index("ba_index", r.BaIndex)
+	v.index("pos_index", r.PosIndex)
+
+	if r.Email != "" && !strings.Contains(string(r.Email), "@") {
+		v.add("email", StatusType5, "invalid email")
+	}
+
+	v.enum("doctype", r.DocType, true)
+	v.enum("pastdoctype", r.PastDocType, true)
+	v.enum("sex", r.Sex, true)
+	v.enum("citizenship", r.Citizenship, true)
+	v.enum("la_country", r.LaCountry, true)
+	v.enum("ra_country", r.RaCountry, true)
+	v.enum("ba_country", r.BaCountry, true)
+	v.enum("pos_country", r.PosCountry, true)
+	v.enum("education", r.Education, true)
+	v.enum("marital", r.Marital, true)
+	v.enum("employersize", r.EmployerSize, true)
+	v.enum("businessindustry", r.BusinessIndustry, true)
+	v.enum("incomeproof", r.IncomeProof, true)
+	v.enum("producttype", r.ProductType, true)
+	v.enum("originalchannel", r.OriginalChannel, true)
+	v.enum("productsumcurrency", r.ProductSumCurrency, true)
+	v.enum("initialsumcurrency", r.InitialSumCurrency, true)
+	v.enum("collateralexistence", r.CollateralExistence, true)
+	v.enum("purchaseexistence", r.PurchaseExistence, true)
+	v.enum("newapplicant", r.NewApplicant, true)
+	v.enum("applicanttype", r.ApplicantType, true)
+	v.enum("responseisneeded", r.ResponseIsNeeded, true)
+	if r.ApplicationStatus != 0 {
+		v.partnerStatus("applicationstatus", r.ApplicationStatus, r.ApplicationStatus == ApplicationStatusType8)
+	}
+	if r.ApplicationFraudStatus != 0 {
+		v.partnerStatus("applicationfraudstatus", r.ApplicationFraudStatus, r.ApplicationFraudStatus == ApplicationFraudStatusType8)
+	}
+	if r.DefaultStatus != 0 {
+		v.partnerStatus("defaultstatus", r.DefaultStatus, r.DefaultStatus == DefaultStatusType8)
+	}
+
+	applicationDate := r.ApplicationDate.Time
+	v.before("birthday", r.Birthday.Time, "applicationdate", applicationDate)
+	v.before("birthday", r.Birthday.Time, "docdate", r.DocDate.Time)
+	v.before("docdate", r.DocDate.Time, "applicationdate", applicationDate)
+	v.before("pastdocdate", r.PastDocDate.Time, "docdate", r.DocDate.Time)
+	v.before("la_date", r.LaDate.Time, "applicationdate", applicationDate)
+	v.before("birthday", r.Birthday.Time, "employment_date", r.EmploymentDate.Time)
+	v.before("employment_date", r.EmploymentDate.Time, "applicationdate", applicationDate)
+	v.before("applicationdate", applicationDate, "tradedate", r.TradeDate.Time)
+
+	return v.err()
+}
+
+func (r *OutputVector) Validate() error {
+	v := new(validator)
+	v.required("applicationid", r.ApplicationID, maxIDLength)
+	v.enum("applicanttype", r.ApplicantType, true)
+	return v.err()
+}
+
+func (r *UpdateCreditStatus) Validate() error {
+	v := new(validator)
+	v.required("applicationid", r.ApplicationID, maxIDLength)
+	v.length("applicantid", r.ApplicantID, maxIDLength)
+	v.partnerStatus("applicationstatus", r.ApplicationStatus, r.ApplicationStatus == ApplicationStatusType8)
+	v.enum("initialsumcurrency", r.InitialSumCurrency, true)
+	v.before("applicationdate", r.ApplicationDate.Time, "tradedate", r.TradeDate.Time)
+	return v.err()
+}
+
+func (r *UpdateFraudStatus) Validate() error {
+	v := new(validator)
+	v.required("applicationid", r.ApplicationID, maxIDLength)
+	v.partnerStatus("applicationfraudstatus", r.ApplicationFraudStatus, r.ApplicationFraudStatus == ApplicationFraudStatusType8)
+	v.length("applicationfraudstatusdescr", r.ApplicationFraudStatusDesc, maxTextLength)
+	return v.err()
+}
+
+func (r *UpdateDefaultStatus) Validate() error {
+	v := new(validator)
+	v.required("applicationid", r.ApplicationID, maxIDLength)
+	v.length("applicantid", r.ApplicantID, maxIDLength)
+	v.partnerStatus("defaultstatus", r.DefaultStatus, r.DefaultStatus == DefaultStatusType8)
+	v.enum("initialsumcurrency", r.InitialSumCurrency, true)
+	v.before("applicationdate", r.ApplicationDate.Time, "tradedate", r.TradeDate.Time)
+	return v.err()
+}
+
+func (r *ProcessingApplication) Validate() error {
+	v := new(validator)
+	v.required("applicationid", r.ApplicationID, maxIDLength)
+	v.enum("applicanttype", r.ApplicantType, true)
+	v.enum("responseisneeded", r.ResponseIsNeeded, true)
+	v.digits("applicanttypenum", EmptyString(r.ApplicantTypeNum))
+	return v.err()
+}
+
+func (r *DeleteApplication) Validate() error {
+	v := new(validator)
+	v.required("applicationid", r.ApplicationID, maxIDLength)
+	v.enum("applicanttype", r.ApplicantType, true)
+	v.digits("applicanttypenum", EmptyString(r.ApplicantTypeNum))
+	return v.err()
+}